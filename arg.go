@@ -1,7 +1,9 @@
 package arg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -16,12 +18,35 @@ var queue = make(workQueue, 0)
 
 var OptionCombination int32 = 0
 
+// Reject unknown arguments start with "-" in all commands, see Command.Strict
+var StrictMode = false
+
+// Execute all the options of a command even if some of them failed,
+// the errors are passed to the ErrorHandler of the command as MultiError,
+// the command is executed if it returns nil
+var ContinueOnError = false
+
+// Recover panics in executors as ExecutorPanicError
+var RecoverPanics = false
+
+// Writer of help and other output, os.Stdout if nil
+var Out io.Writer
+
+// Writer of error messages and warnings, os.Stderr if nil
+var Err io.Writer
+
 // The Command to be executed
 var command = RootCommand
 
 // The Arguments of the Command
 var commandArgs = []string{command.Name}
 
+// Index of commandArgs in arguments
+var commandArgsIndex = []int{0}
+
+// The arguments being parsed, arguments[0] is the programme
+var arguments = os.Args
+
 func AddHelpCommandArg(h string) {
 	HelpCommandArgs[h] = true
 }
@@ -70,12 +95,14 @@ func Add(isCmd bool, arg []string, order, size, priority int, describe, describe
 				}
 				args.Commands[v] = NewCommandFull(order, v, father, describe, describeBrief, help,
 					usage, size, executor, errExecutor)
+				args.Commands[v].parent = args
 			} else {
 				if args.Options == nil {
 					args.Options = NewOptions()
 				}
 				args.Options[v] = NewOptionFull(order, v, father, size, priority, describe, describeBrief,
 					help, usage, executor, errExecutor)
+				args.Options[v].parent = args
 			}
 			return nil
 		}
@@ -86,7 +113,7 @@ func Add(isCmd bool, arg []string, order, size, priority int, describe, describe
 				continue
 			}
 		}
-		return ErrWrongArgPath
+		return &ParseError{Err: ErrWrongArgPath, Args: arg, Token: v, Index: k, Command: father}
 	}
 	return nil
 }
@@ -99,34 +126,78 @@ func EnableOptionCombination() {
 //
 // Parse os.Args use RootCommand
 func Parse() (err error) {
-	err = parse(command, os.Args[1:])
+	return ParseContext(context.Background())
+}
+
+// Parse os.Args use RootCommand with ctx
+func parseArgs(ctx context.Context) (err error) {
+	if len(CompleteCommandName) != 0 && len(os.Args) > 1 && os.Args[1] == CompleteCommandName {
+		printCompletion(RootCommand, os.Args[2:])
+		return ErrComplete
+	}
+	if err = RootCommand.Check(); err != nil {
+		return err
+	}
+	// reset parser
+	queue = make(workQueue, 0)
+	command = RootCommand
+	commandArgs = []string{command.Name}
+	commandArgsIndex = []int{0}
+	timeoutArg = 0
+	dryRun = ""
+
+	args := os.Args[1:]
+	arguments = append([]string{os.Args[0]}, args...)
+	err = parse(command, args)
 	if err != nil {
 		return err
 	}
 	if command.Size != -1 && len(commandArgs) != command.Size+1 {
-		if command.ErrorHandler == nil {
-			fmt.Printf(TplNeedMoreArguments, "command", command.Name, command.Size)
-			return ErrNeedMoreArguments
-		} else if err = command.ErrorHandler(ErrNeedMoreArguments); err != nil {
+		e := &ParseError{
+			Err:      ErrNeedMoreArguments,
+			Args:     arguments,
+			Index:    len(arguments),
+			Command:  command.FullName(),
+			Expected: command.Size,
+			Actual:   len(commandArgs) - 1,
+		}
+		if len(commandArgs) > command.Size+1 {
+			e.Index = commandArgsIndex[command.Size+1]
+			e.Token = arguments[e.Index]
+		}
+		if handler := command.errorHandler(ctx); handler == nil {
+			printError(command, e.Diagnostic())
+			return e
+		} else if err = handler(e); err != nil {
 			return err
 		}
 		return nil
 	}
 	queue.sort()
-	err = queue.exec()
-	if err != nil {
-		return err
-	}
-	if command.Executor == nil {
-		return nil
+	if IsDryRun() {
+		printPlan(command, newPlan(command, queue, commandArgs))
+		if !command.Simulate {
+			return ErrDryRun
+		}
 	}
-	err = command.Executor(commandArgs)
+	err = queue.exec(ctx, command.continueOnError())
 	if err != nil {
-		if command.ErrorHandler != nil {
-			err = command.ErrorHandler(err)
+		handler := command.errorHandler(ctx)
+		if ctx.Err() != nil {
+			// cancelled, the command is not executed
+			if handler != nil {
+				err = handler(err)
+			}
+			return queue.unwind(err)
+		}
+		if _, ok := err.(MultiError); !ok || handler == nil {
+			return queue.unwind(err)
+		}
+		if err = handler(err); err != nil {
+			return queue.unwind(err)
 		}
 	}
-	return err
+	return queue.unwind(run(ctx, command, commandArgs))
 }
 
 // Parse "args" use "cmd"
@@ -142,6 +213,8 @@ func parse(cmd *Command, args []string) error {
 			command = c
 			// reset command args
 			commandArgs = []string{c.Name}
+			commandArgsIndex = []int{argIndex(args)}
+			warnDeprecated(c, MsgCommand, c.FullName(), c.Deprecated, &c.warned)
 
 			return parse(c, args[1:])
 		}
@@ -150,26 +223,60 @@ func parse(cmd *Command, args []string) error {
 	if cmd.Options != nil {
 		opt, ok := cmd.Options[args[0]]
 		if ok {
+			warnDeprecated(cmd, MsgOption, opt.FullName(), opt.Deprecated, &opt.warned)
+			if opt.terminal && !completing {
+				return opt.executor(parseCtx)(args[:1])
+			}
+			if completing && (opt.Size == -1 || len(args) < 1+opt.Size) {
+				completeOption, completeOptionArgs = opt, args[1:]
+				return nil
+			}
 			if opt.Size == -1 {
-				queue.add(opt.Priority, opt.Executor, opt.ErrorExecutor, args[:])
+				queue.add(opt, args[:])
 				return nil
 			}
 			if len(args) < 1+opt.Size {
-				if opt.ErrorExecutor == nil {
-					fmt.Printf(TplNeedMoreArguments, "option", opt.Father+" "+opt.Name, opt.Size)
-					return ErrNeedMoreArguments
-				} else if err := opt.ErrorExecutor(ErrNeedMoreArguments); err != nil {
+				e := &ParseError{
+					Err:      ErrNeedMoreArguments,
+					Args:     arguments,
+					Token:    args[0],
+					Index:    argIndex(args),
+					Command:  cmd.FullName(),
+					Option:   opt.Name,
+					Expected: opt.Size,
+					Actual:   len(args) - 1,
+				}
+				if handler := opt.errorHandler(parseCtx); handler == nil {
+					printError(cmd, e.Diagnostic())
+					return e
+				} else if err := handler(e); err != nil {
 					return err
 				}
-				queue.add(opt.Priority, opt.Executor, opt.ErrorExecutor, args[:])
+				queue.add(opt, args[:])
 				return nil
 			}
-			queue.add(opt.Priority, opt.Executor, opt.ErrorExecutor, args[:1+opt.Size])
+			queue.add(opt, args[:1+opt.Size])
 			return parse(cmd, args[1+opt.Size:])
 		}
 	}
 
+	if n, err := parseBuiltin(cmd, args); err != nil {
+		if completing {
+			// toComplete is the value of a built-in option, no candidates
+			completeOption, completeOptionArgs = &Option{}, nil
+			return nil
+		}
+		printError(cmd, err.Diagnostic())
+		return err
+	} else if n != 0 {
+		return parse(cmd, args[n:])
+	}
+
 	if h, ok := HelpCommandArgs[args[0]]; ok {
+		if completing {
+			completeHelp = true
+			return nil
+		}
 		if h && len(args) >= 2 {
 			if cmd.Commands != nil {
 				if c, ok := cmd.Commands[args[1]]; ok {
@@ -214,12 +321,65 @@ func parse(cmd *Command, args []string) error {
 				}
 				op = fmt.Sprintf(format, v)
 				o, _ := cmd.Options[op]
-				queue.add(o.Priority, o.Executor, o.ErrorExecutor, []string{op})
+				warnDeprecated(cmd, MsgOption, o.FullName(), o.Deprecated, &o.warned)
+				queue.add(o, []string{op})
 			}
 			return parse(cmd, args[1:])
 		}
 	}
 
+	if e := unknownArg(cmd, args[0], len(commandArgs) == 1); e != nil && !completing {
+		e.Args = arguments
+		e.Index = argIndex(args)
+		if handler := cmd.errorHandler(parseCtx); handler == nil {
+			printError(cmd, e.Diagnostic())
+			return e
+		} else if err := handler(e); err != nil {
+			return err
+		}
+	}
+
 	commandArgs = append(commandArgs, args[0])
+	commandArgsIndex = append(commandArgsIndex, argIndex(args))
 	return parse(cmd, args[1:])
 }
+
+// Parse the built-in options at args[0], ColorOption, DryRunOption and TimeoutOption,
+// return the number of arguments used
+//
+// They are options of all commands, but not the arguments of a command whose Size is -1
+func parseBuiltin(cmd *Command, args []string) (int, *ParseError) {
+	if cmd.Size == -1 && len(commandArgs) > 1 {
+		return 0, nil
+	}
+	if n := parseColorOption(args); n != 0 {
+		return n, nil
+	}
+	if n := parseDryRunOption(args); n != 0 {
+		return n, nil
+	}
+	return parseTimeoutOption(cmd, args)
+}
+
+// Index of args[0] in arguments
+func argIndex(args []string) int {
+	return len(arguments) - len(args)
+}
+
+// Print the deprecation warning of a Command or Option to the Err of cmd, only once
+func warnDeprecated(cmd *Command, kind, name, deprecated string, warned *bool) {
+	if len(deprecated) == 0 || *warned || completing {
+		return
+	}
+	*warned = true
+	printError(cmd, Tf(MsgDeprecated, T(kind), name, deprecated)+"\n")
+}
+
+// Print the message of an error to the Err of cmd, colorized by ColorTheme
+func printError(cmd *Command, msg string) {
+	w := cmd.errOut()
+	if colorEnabled(w) {
+		msg = colorize(ColorTheme.Error, msg)
+	}
+	_, _ = fmt.Fprint(w, msg)
+}
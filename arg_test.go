@@ -1,12 +1,23 @@
 package arg
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
+func init() {
+	// the outputs are in English, whatever LANG is
+	Locale = "en"
+}
+
 func Example() {
 	var err error
 	var Err1 = errors.New("error 1") // handled
@@ -409,3 +420,1229 @@ func ExampleAddHelpCommandArg() {
 	//        fi version v1 u1
 
 }
+
+func ExampleFuncCompleter() {
+	os.Args = []string{"fi", CompleteCommandName, "deploy", "-target", "p"}
+	RootCommand = NewCommand("fi", "")
+
+	_ = AddCommand([]string{"deploy"}, 1, 0, "deploy the programme", "deploy", "", "",
+		func(str []string) error {
+			fmt.Println("deploy", str)
+			return nil
+		}, nil)
+	_ = AddOption([]string{"deploy", "-target"}, 1, 1, 100, "deploy target", "target", "",
+		"[target]", nil, nil)
+	RootCommand.Commands["deploy"].Options["-target"].Completer = func(args []string, toComplete string) []string {
+		return []string{"production", "preview", "staging"}
+	}
+
+	err := Parse()
+	if err != ErrComplete {
+		fmt.Println(err)
+	}
+
+	os.Args = []string{"fi", CompleteCommandName, "d"}
+	_ = Parse()
+
+	// Output:
+	// production
+	// preview
+	// deploy
+}
+
+func ExampleCommand_GenerateMan() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.DescribeBrief = "file tool"
+	_ = AddCommand([]string{"version"}, 1, 1, "check version", "check programme version",
+		"", "[version]", func(str []string) error {
+			return nil
+		}, nil)
+	_ = AddOption([]string{"version", "-short"}, 1, 0, 100, "print the short version",
+		"short version", "", "", nil, nil)
+	ManDate = "October 2026"
+
+	fmt.Print(RootCommand.Commands["version"].GenerateMan())
+
+	// Output:
+	// .TH "FI-VERSION" "1" "October 2026" "" ""
+	// .SH NAME
+	// fi\-version \- check programme version
+	// .SH SYNOPSIS
+	// .B fi version
+	// [version]
+	// .br
+	// .B fi version
+	// <option> [arguments]
+	// .br
+	// .SH DESCRIPTION
+	// check version
+	// .SH OPTIONS
+	// .TP
+	// \fB\-short\fR
+	// print the short version
+	// .SH SEE ALSO
+	// \fBfi\fR(1)
+}
+
+func ExampleCommand_GenerateMarkdown() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.DescribeBrief = "file tool"
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file to fi", "build a file",
+		"", "[filename]", func(str []string) error {
+			return nil
+		}, nil)
+	_ = AddOption([]string{"build", "-type"}, 1, 1, 100, "type of the target",
+		"target type", "", "[type]", nil, nil)
+	build := RootCommand.Commands["build"]
+	build.Options["-type"].Default = "tgz"
+	build.Options["-type"].Env = "FI_TYPE"
+	build.Example = "fi build -type zip file"
+	MarkdownFrontMatter = func(c *Command) string {
+		return "---\ntitle: " + c.FullName() + "\n---\n\n"
+	}
+
+	fmt.Print(build.GenerateMarkdown())
+	MarkdownFrontMatter = nil
+
+	// Output:
+	// ---
+	// title: fi build
+	// ---
+	//
+	// # fi build
+	//
+	// build a file
+	//
+	// ## Synopsis
+	//
+	// build a file to fi
+	//
+	// ```
+	// fi build [filename]
+	// fi build <option> [arguments]
+	// ```
+	//
+	// ## Options
+	//
+	// | Name | Arguments | Default | Env | Description |
+	// | --- | --- | --- | --- | --- |
+	// | `-type` | [type] | tgz | FI_TYPE | target type |
+	//
+	// ## Examples
+	//
+	// ```
+	// fi build -type zip file
+	// ```
+	//
+	// ## See Also
+	//
+	// * [fi](fi.md) - file tool
+}
+
+func ExampleCommand_GenerateHelp() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Describe = "fi is a file tool,\nit builds files"
+	RootCommand.Usage = "[filename]"
+	RootCommand.Executor = func(str []string) error {
+		return nil
+	}
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file to fi", "build a file",
+		"", "[filename]", nil, nil)
+	_ = AddCommand([]string{"encrypt"}, 2, 1, "encrypt a file", "encrypt a file",
+		"", "[filename]", nil, nil)
+	_ = AddOption([]string{"-o"}, 1, 1, 100, "specify out file", "specify out file", "",
+		"[filename]", nil, nil)
+	_ = AddOption([]string{"-v"}, 2, 0, 100, "verbose", "print details", "",
+		"", nil, nil)
+	AddHelpCommandArg("help")
+	RootCommand.Commands["encrypt"].HelpTemplate = "{{.FullName}}: {{.DescribeBrief}}\n"
+	RootCommand.GenerateHelp()
+
+	fmt.Print(RootCommand.Help)
+	fmt.Print(RootCommand.Commands["encrypt"].Help)
+
+	// Output:
+	// fi
+	//
+	//     fi is a file tool,
+	//     it builds files
+	//
+	// Usage:
+	//
+	//         fi [filename]
+	//         fi <command> [arguments]
+	//         fi <option>  [arguments]
+	//
+	// The commands are:
+	//
+	//         build    build a file
+	//         encrypt  encrypt a file
+	//
+	// Use "fi help <command>" for more information about a command.
+	//
+	// The options are:
+	//
+	//         -o  [filename]
+	//               specify out file
+	//         -v
+	//               print details
+	//
+	// Use "fi help <option>" for more information about a option.
+	//
+	// fi encrypt: encrypt a file
+}
+
+func ExampleCommand_GenerateHelp_wrap() {
+	_ = os.Setenv("COLUMNS", "40")
+	defer os.Unsetenv("COLUMNS")
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Describe = "fi is a file tool, it builds files and encrypts files\n\n" +
+		"Example:\n    fi build file.txt file.fi"
+	_ = AddCommand([]string{"build"}, 1, 1, "", "build a file to fi, the origin file is kept",
+		"", "[filename]", nil, nil)
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	fmt.Print(RootCommand.Help)
+
+	// Output:
+	// fi
+	//
+	//     fi is a file tool, it builds files
+	//     and encrypts files
+	//
+	//     Example:
+	//         fi build file.txt file.fi
+	//
+	// Usage:
+	//
+	//         fi <command> [arguments]
+	//
+	// The commands are:
+	//
+	//         build  build a file to fi, the
+	//                origin file is kept
+	//
+	// Use "fi help <command>" for more information about a command.
+}
+
+func ExampleCommand_GenerateHelp_wide() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"构建"}, 1, 1, "", "构建文件", "", "", nil, nil)
+	_ = AddCommand([]string{"build"}, 2, 1, "", "build a file", "", "", nil, nil)
+	_ = AddCommand([]string{"🚀"}, 3, 1, "", "deploy", "", "", nil, nil)
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	fmt.Print(RootCommand.Help)
+
+	// Output:
+	// fi
+	//
+	// Usage:
+	//
+	//         fi <command> [arguments]
+	//
+	// The commands are:
+	//
+	//         构建   构建文件
+	//         build  build a file
+	//         🚀     deploy
+	//
+	// Use "fi help <command>" for more information about a command.
+}
+
+func ExampleEnableColor() {
+	os.Args = []string{"fi", "--color=never", "help"}
+	RootCommand = NewCommand("fi", "")
+	_ = AddOption([]string{"-v"}, 1, 0, 100, "verbose", "print details", "", "", nil, nil)
+	EnableColor()
+	ColorMode = ColorAlways
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	err := Parse()
+	if err != ErrHelp {
+		fmt.Println(err)
+	}
+	ColorTheme = nil
+	ColorMode = ColorAuto
+
+	// Output:
+	// fi
+	//
+	// Usage:
+	//
+	//         fi <option>  [arguments]
+	//
+	// The options are:
+	//
+	//         -v
+	//               print details
+	//
+	// Use "fi help <option>" for more information about a option.
+}
+
+func ExampleParse_suggestion() {
+	os.Args = []string{"fi", "biuld", "file"}
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Size = -1
+	RootCommand.Executor = func(str []string) error {
+		fmt.Println("Root Command", str)
+		return nil
+	}
+	RootCommand.ErrorHandler = func(err error) error {
+		fmt.Println(err)
+		return err
+	}
+	_ = AddCommand([]string{"build"}, 1, 1, "", "build a file", "", "", nil, nil)
+	_ = AddCommand([]string{"encrypt"}, 1, 1, "", "encrypt a file", "", "", nil, nil)
+
+	err := Parse()
+	if errors.Is(err, ErrUnknownCommand) {
+		fmt.Println("---")
+	}
+
+	// Output:
+	// unknown command "biuld" for "fi"
+	//
+	// Did you mean this?
+	// 	build
+	// ---
+}
+
+func ExampleParse_strict() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Strict = true
+	RootCommand.Size = -1
+	RootCommand.Executor = func(str []string) error {
+		fmt.Println("Root Command", str)
+		return nil
+	}
+	RootCommand.ErrorHandler = func(err error) error {
+		fmt.Println(err)
+		return err
+	}
+	_ = AddOption([]string{"-verbose"}, 1, 0, 100, "", "", "", "", nil, nil)
+	_ = AddOption([]string{"-o"}, 1, 0, 100, "", "", "", "", nil, nil)
+
+	os.Args = []string{"fi", "-10", "-", "-1", "file"}
+	_ = Parse()
+	os.Args = []string{"fi", "-x", "file"}
+	err := Parse()
+	fmt.Println(errors.Is(err, ErrUnknownOption))
+
+	// Output:
+	// Root Command [fi -10 - -1 file]
+	// unknown option "-x" for "fi"
+	// true
+}
+
+func ExampleParseError() {
+	os.Args = []string{"fi", "build", "-tpye", "file"}
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"build"}, 1, 1, "", "build a file", "", "", func(str []string) error {
+		return nil
+	}, func(err error) error {
+		var e *ParseError
+		if errors.As(err, &e) {
+			fmt.Print(e.Diagnostic())
+			fmt.Println(e.Index, e.Command, e.Suggestions)
+		}
+		return err
+	})
+	_ = AddOption([]string{"build", "-type"}, 1, 1, 100, "", "", "", "[type]", nil, nil)
+
+	_ = Parse()
+
+	os.Args = []string{"fi", "build", "file", "-type"}
+	RootCommand.Commands["build"].Options["-type"].ErrorExecutor = func(err error) error {
+		fmt.Print(err.(*ParseError).Diagnostic())
+		fmt.Println(errors.Is(err, ErrNeedMoreArguments))
+		return err
+	}
+
+	_ = Parse()
+
+	// Output:
+	// fi build -tpye file
+	//          ^^^^^
+	// unknown option "-tpye" for "fi build"
+	//
+	// Did you mean this?
+	// 	-type
+	// 2 fi build [-type]
+	// fi build file -type
+	//               ^^^^^
+	// The option [fi build -type] requires 1 arguments to execute
+	// true
+}
+
+func ExampleCommand_PrintHelp() {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Out = out
+	RootCommand.Err = errOut
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			return nil
+		}, nil)
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	os.Args = []string{"fi", "help", "build"}
+	_ = Parse()
+	os.Args = []string{"fi", "build"}
+	_ = Parse()
+
+	fmt.Print("Out:", out.String())
+	fmt.Print("Err:\n", errOut.String())
+
+	// Output:
+	// Out:
+	// fi build
+	//
+	//     build a file
+	//
+	// Usage:
+	//
+	//         fi build [filename]
+	//
+	// Err:
+	// fi build
+	//          ^
+	// The command [fi build] requires 1 arguments to execute
+}
+
+func ExampleParse_deprecated() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Err = os.Stdout
+	RootCommand.Size = -1
+	RootCommand.Executor = func(str []string) error {
+		fmt.Println("Root Command", str)
+		return nil
+	}
+	_ = AddCommand([]string{"debug"}, 1, 0, "", "debug", "", "", func(str []string) error {
+		fmt.Println("debug")
+		return nil
+	}, nil)
+	_ = AddOption([]string{"-out"}, 1, 1, 100, "", "specify out file", "", "[filename]",
+		func(str []string) error {
+			fmt.Println("out", str[1])
+			return nil
+		}, nil)
+	_ = AddOption([]string{"-output"}, 2, 1, 100, "", "specify out file", "", "[filename]", nil, nil)
+	RootCommand.Commands["debug"].Hidden = true
+	RootCommand.Options["-out"].Deprecated = "use -output instead"
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	os.Args = []string{"fi", "-out", "a", "-out", "b"}
+	_ = Parse()
+	os.Args = []string{"fi", "debug"}
+	_ = Parse()
+	fmt.Print(RootCommand.Help)
+
+	// Output:
+	// The option [fi -out] is deprecated, use -output instead
+	// out a
+	// out b
+	// Root Command [fi]
+	// debug
+	//
+	// fi
+	//
+	// Usage:
+	//
+	//         fi
+	//         fi <option>  [arguments]
+	//
+	// The options are:
+	//
+	//         -out     [filename]
+	//                    specify out file (deprecated: use -output instead)
+	//         -output  [filename]
+	//                    specify out file
+	//
+	// Use "fi help <option>" for more information about a option.
+}
+
+func ExampleCommand_GenerateHelp_group() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Executor = func(str []string) error {
+		return nil
+	}
+	_ = AddOption([]string{"-i"}, 1, 1, 100, "", "input file", "", "[filename]", nil, nil)
+	_ = AddOption([]string{"-o"}, 2, 1, 100, "", "output file", "", "[filename]", nil, nil)
+	_ = AddOption([]string{"-f"}, 1, 1, 100, "", "output format", "", "[format]", nil, nil)
+	_ = AddOption([]string{"-v"}, 3, 0, 100, "", "print details", "", "", nil, nil)
+	RootCommand.Options["-i"].Group = "Input options"
+	RootCommand.Options["-o"].Group = "Output options"
+	RootCommand.Options["-f"].Group = "Output options"
+	RootCommand.GroupOrder = []string{"Output options", "Input options"}
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	fmt.Print(RootCommand.Help)
+
+	// Output:
+	// fi
+	//
+	// Usage:
+	//
+	//         fi
+	//         fi <option>  [arguments]
+	//
+	// The options are:
+	//
+	//         -v
+	//               print details
+	//
+	// Output options:
+	//
+	//         -f  [format]
+	//               output format
+	//         -o  [filename]
+	//               output file
+	//
+	// Input options:
+	//
+	//         -i  [filename]
+	//               input file
+	//
+	// Use "fi help <option>" for more information about a option.
+}
+
+func ExampleEnableVersion() {
+	out := &bytes.Buffer{}
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Out = out
+	RootCommand.Size = 1
+	_ = EnableVersion("1.2.0", false)
+
+	os.Args = []string{"fi", "--version"}
+	err := Parse()
+	fmt.Println(err == ErrVersion)
+	fmt.Print(strings.SplitAfter(out.String(), "\n")[0])
+
+	out.Reset()
+	os.Args = []string{"fi", "version", "-json"}
+	_ = Parse()
+	info := VersionInfo{}
+	_ = json.Unmarshal(out.Bytes(), &info)
+	fmt.Println(info.Version, info.GoVersion == runtime.Version())
+
+	// Output:
+	// true
+	// fi 1.2.0
+	// 1.2.0 true
+}
+
+func ExampleAddTranslations() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Err = os.Stdout
+	RootCommand.Size = -1
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			return nil
+		}, nil)
+	_ = AddCommand([]string{"show"}, 2, 0, "usage", "usage", "", "", nil, nil)
+	AddTranslations("zh", Messages{
+		"build a file": "构建文件",
+	})
+	Locale = "zh_CN.UTF-8"
+	defer func() { Locale = "en" }()
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	os.Args = []string{"fi", "help"}
+	_ = Parse()
+	os.Args = []string{"fi", "build"}
+	_ = Parse()
+
+	// Output:
+	// fi
+	//
+	// 用法：
+	//
+	//         fi <command> [arguments]
+	//
+	// 命令：
+	//
+	//         build  构建文件
+	//         show   usage
+	//
+	// 使用 "fi help <命令>" 查看命令的详细信息。
+	//
+	// fi build
+	//          ^
+	// 命令 [fi build] 需要 1 个参数
+}
+
+func ExampleCommand_PersistentPreRun() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.PersistentPreRun = func(str []string) error {
+		fmt.Println("fi PersistentPreRun", str)
+		return nil
+	}
+	RootCommand.PersistentPostRun = func(str []string) error {
+		fmt.Println("fi PersistentPostRun", str)
+		return nil
+	}
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			fmt.Println("build", str)
+			return errors.New("build failed")
+		}, func(err error) error {
+			fmt.Println("build error:", err)
+			return err
+		})
+	build := RootCommand.Commands["build"]
+	build.PreRun = func(str []string) error {
+		fmt.Println("build PreRun", str)
+		return nil
+	}
+	build.PostRun = func(str []string) error {
+		fmt.Println("build PostRun", str)
+		return nil
+	}
+
+	os.Args = []string{"fi", "build", "file"}
+	fmt.Println(Parse())
+
+	// Output:
+	// fi PersistentPreRun [build file]
+	// build PreRun [build file]
+	// build [build file]
+	// build PostRun [build file]
+	// fi PersistentPostRun [build file]
+	// build error: build failed
+	// build failed
+}
+
+func ExampleUse() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			fmt.Println("build", str)
+			return nil
+		}, nil)
+	_ = AddOption([]string{"build", "-del"}, 1, 0, 10, "delete file", "delete file", "", "",
+		func(str []string) error {
+			fmt.Println("delete", str)
+			return nil
+		}, nil)
+	Use(WithTarget(func(cmd *Command, opt *Option, next FuncExecutor) FuncExecutor {
+		return func(str []string) error {
+			name := cmd.FullName()
+			if opt != nil {
+				name = opt.FullName()
+			}
+			fmt.Println("start", name)
+			defer fmt.Println("end", name)
+			return next(str)
+		}
+	}))
+	defer func() { middlewares = make([]Middleware, 0) }()
+	RootCommand.Commands["build"].Use(func(next FuncExecutor) FuncExecutor {
+		return func(str []string) error {
+			fmt.Println("auth")
+			return next(str)
+		}
+	})
+
+	os.Args = []string{"fi", "build", "-del", "file"}
+	_ = Parse()
+
+	// Output:
+	// start fi build -del
+	// auth
+	// delete [-del]
+	// end fi build -del
+	// start fi build
+	// auth
+	// build [build file]
+	// end fi build
+}
+
+func ExampleExecutorPanicError() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			var m map[string]int
+			m[str[1]] = 1
+			return nil
+		}, func(err error) error {
+			var e *ExecutorPanicError
+			if errors.As(err, &e) {
+				fmt.Println("command:", e.Command)
+				fmt.Println("stack:", len(e.Stack) != 0)
+			}
+			return err
+		})
+	RecoverPanics = true
+	defer func() { RecoverPanics = false }()
+
+	os.Args = []string{"fi", "build", "file"}
+	fmt.Println(Parse())
+
+	// Output:
+	// command: fi build
+	// stack: true
+	// panic in command [fi build]: assignment to entry in nil map
+}
+
+func ExampleMultiError() {
+	errName := errors.New("invalid name")
+	errAge := errors.New("invalid age")
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"user"}, 1, 0, "add a user", "add a user", "", "",
+		func(str []string) error {
+			fmt.Println("user added")
+			return nil
+		}, func(err error) error {
+			fmt.Println("invalid name:", errors.Is(err, errName))
+			fmt.Println("invalid age:", errors.Is(err, errAge))
+			return err
+		})
+	_ = AddOption([]string{"user", "-name"}, 1, 1, 20, "name", "name", "", "[name]",
+		func(str []string) error {
+			return errName
+		}, nil)
+	_ = AddOption([]string{"user", "-age"}, 1, 1, 10, "age", "age", "", "[age]",
+		func(str []string) error {
+			return errAge
+		}, nil)
+	RootCommand.Commands["user"].ContinueOnError = true
+
+	os.Args = []string{"fi", "user", "-name", "", "-age", "-1"}
+	fmt.Println(Parse())
+
+	// Output:
+	// invalid name: true
+	// invalid age: true
+	// invalid name
+	// invalid age
+}
+
+func ExampleCommand_Check() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"deploy"}, 1, 0, "deploy", "deploy", "", "",
+		func(str []string) error {
+			fmt.Println("deploy")
+			return nil
+		}, nil)
+	for _, v := range []string{"-config", "-db", "-cache"} {
+		name := v
+		_ = AddOption([]string{"deploy", name}, 1, 0, 10, name, name, "", "",
+			func(str []string) error {
+				fmt.Println("open", name)
+				return nil
+			}, nil)
+	}
+	deploy := RootCommand.Commands["deploy"]
+	_ = deploy.Options["-db"].RunAfter("-config")
+	_ = deploy.Options["-cache"].RunAfter("-db")
+	fmt.Println(deploy.Options["-config"].RunAfter("-cache"))
+	fmt.Println(deploy.Options["-config"].RunBefore("-cahce"))
+	fmt.Println(len(deploy.Options["-config"].After))
+
+	os.Args = []string{"fi", "deploy", "-cache", "-db", "-config"}
+	_ = Parse()
+
+	deploy.Options["-config"].After = []string{"-cache"}
+	fmt.Println(RootCommand.Check())
+
+	// Output:
+	// dependency cycle in "fi deploy": -cache -> -config -> -db -> -cache
+	// unknown option "-cahce" in Before of "fi deploy -config"
+	// 0
+	// open -config
+	// open -db
+	// open -cache
+	// deploy
+	// dependency cycle in "fi deploy": -cache -> -config -> -db -> -cache
+}
+
+func ExampleOption_Concurrent() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"serve"}, 1, 0, "serve", "serve", "", "",
+		func(str []string) error {
+			fmt.Println("serve")
+			return nil
+		}, nil)
+	started := make(chan string, 2)
+	warm := func(str []string) error {
+		started <- str[0]
+		// wait until both are started
+		for len(started) != 2 {
+			runtime.Gosched()
+		}
+		return nil
+	}
+	_ = AddOption([]string{"serve", "-cache"}, 1, 0, 10, "warm cache", "warm cache", "", "", warm, nil)
+	_ = AddOption([]string{"serve", "-index"}, 1, 0, 10, "warm index", "warm index", "", "", warm, nil)
+	_ = AddOption([]string{"serve", "-check"}, 1, 0, 10, "check", "check", "", "",
+		func(str []string) error {
+			return errors.New("check failed")
+		}, nil)
+	serve := RootCommand.Commands["serve"]
+	serve.Options["-cache"].Concurrent = true
+	serve.Options["-index"].Concurrent = true
+
+	os.Args = []string{"fi", "serve", "-cache", "-index"}
+	fmt.Println(Parse(), len(started))
+
+	Parallel = true
+	MaxParallel = 2
+	defer func() {
+		Parallel = false
+		MaxParallel = 0
+	}()
+	os.Args = []string{"fi", "serve", "-check", "-cache"}
+	started = make(chan string, 2)
+	started <- "-index"
+	fmt.Println(Parse())
+
+	// Output:
+	// serve
+	// <nil> 2
+	// check failed
+}
+
+func ExampleParseContext() {
+	RootCommand = NewCommand("fi", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = AddCommand([]string{"sync"}, 1, 0, "sync files", "sync files", "", "", nil, nil)
+	RootCommand.Commands["sync"].ContextExecutor = func(ctx context.Context, str []string) error {
+		// cancelled by Ctrl-C if HandleSignals is true
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	RootCommand.Commands["sync"].ContextErrorHandler = func(ctx context.Context, err error) error {
+		return fmt.Errorf("sync: %w", err)
+	}
+	OnCancel(func() {
+		fmt.Println("cleanup")
+	})
+	defer func() { cleanups = make([]func(), 0) }()
+
+	os.Args = []string{"fi", "sync"}
+	fmt.Println(ParseContext(ctx))
+
+	// Output:
+	// cleanup
+	// sync: context canceled
+}
+
+func ExampleEnableTimeout() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Err = os.Stdout
+	_ = AddCommand([]string{"sync"}, 1, 0, "sync files", "sync files", "", "", nil,
+		func(err error) error {
+			fmt.Println("timeout:", errors.Is(err, ErrTimeout))
+			return err
+		})
+	RootCommand.Commands["sync"].ContextExecutor = func(ctx context.Context, str []string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	RootCommand.Commands["sync"].Timeout = time.Hour
+	EnableTimeout()
+	defer func() { TimeoutOption = "" }()
+
+	os.Args = []string{"fi", "sync", "--timeout=10ms"}
+	fmt.Println(Parse())
+	os.Args = []string{"fi", "sync", "--timeout", "soon"}
+	_ = Parse()
+
+	// Output:
+	// timeout: true
+	// timeout: fi sync after 10ms
+	// fi sync --timeout soon
+	//                   ^^^^
+	// time: invalid duration "soon"
+}
+
+func ExampleEnableDryRun() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"build"}, 1, 2, "build a file", "build a file", "", "[ori] [target]",
+		func(str []string) error {
+			if IsDryRun() {
+				fmt.Println("would build", str[1:])
+				return nil
+			}
+			fmt.Println("build", str[1:])
+			return nil
+		}, nil)
+	_ = AddOption([]string{"build", "-type"}, 1, 1, 10, "type", "type", "", "[type]",
+		func(str []string) error {
+			fmt.Println("type", str[1])
+			return nil
+		}, nil)
+	_ = AddOption([]string{"build", "-del"}, 1, 0, 20, "delete", "delete", "", "",
+		func(str []string) error {
+			fmt.Println("delete")
+			return nil
+		}, nil)
+	EnableDryRun()
+	defer func() { DryRunOption = "" }()
+
+	os.Args = []string{"fi", "build", "--dry-run", "-type", "tgz", "file1", "-del", "file2"}
+	fmt.Println(Parse())
+	os.Args = []string{"fi", "build", "--dry-run=json", "file1", "file2"}
+	_ = Parse()
+	RootCommand.Commands["build"].Simulate = true
+	os.Args = []string{"fi", "build", "--dry-run", "file1", "file2"}
+	_ = Parse()
+
+	// Output:
+	// command: fi build
+	// options:
+	//     -del
+	//     -type tgz
+	// args: file1 file2
+	// dry run
+	// {
+	//   "command": "fi build",
+	//   "options": [],
+	//   "args": [
+	//     "file1",
+	//     "file2"
+	//   ]
+	// }
+	// command: fi build
+	// args: file1 file2
+	// would build [file1 file2]
+}
+
+func ExampleOption_Rollback() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"deploy"}, 1, 0, "deploy", "deploy", "", "",
+		func(str []string) error {
+			return errors.New("deploy failed")
+		}, nil)
+	_ = AddOption([]string{"deploy", "-lock"}, 1, 0, 20, "lock", "lock", "", "",
+		func(str []string) error {
+			fmt.Println("lock")
+			return nil
+		}, nil)
+	_ = AddOption([]string{"deploy", "-tmp"}, 1, 0, 10, "create temp dir", "create temp dir", "", "",
+		func(str []string) error {
+			fmt.Println("create temp dir")
+			return nil
+		}, nil)
+	deploy := RootCommand.Commands["deploy"]
+	deploy.Options["-lock"].Finally = func(str []string) error {
+		fmt.Println("unlock")
+		return nil
+	}
+	deploy.Options["-tmp"].Rollback = func(str []string) error {
+		fmt.Println("remove temp dir")
+		return nil
+	}
+
+	os.Args = []string{"fi", "deploy", "-tmp", "-lock"}
+	fmt.Println(Parse())
+
+	// Output:
+	// lock
+	// create temp dir
+	// remove temp dir
+	// unlock
+	// deploy failed
+}
+
+func ExampleParse_suggestionNumber() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Size = -1
+	RootCommand.Executor = func(str []string) error {
+		fmt.Println("Root Command", str)
+		return nil
+	}
+	RootCommand.ErrorHandler = func(err error) error {
+		fmt.Println(err)
+		return err
+	}
+	_ = AddOption([]string{"-v"}, 1, 0, 100, "", "verbose", "", "", nil, nil)
+	_ = AddOption([]string{"-o"}, 1, 0, 100, "", "output", "", "", nil, nil)
+
+	os.Args = []string{"fi", "-1", "-", "file"}
+	_ = Parse()
+	os.Args = []string{"fi", "-w", "-n"}
+	_ = Parse()
+	os.Args = []string{"fi", "-V"}
+	_ = Parse()
+
+	// Output:
+	// Root Command [fi -1 - file]
+	// Root Command [fi -w -n]
+	// unknown option "-V" for "fi"
+	//
+	// Did you mean this?
+	// 	-v
+}
+
+func ExampleParseContext_cancel() {
+	RootCommand = NewCommand("fi", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = AddCommand([]string{"sync"}, 1, 0, "sync files", "sync files", "", "",
+		func(str []string) error {
+			fmt.Println("command ran")
+			return nil
+		}, func(err error) error {
+			fmt.Println("canceled:", errors.Is(err, context.Canceled))
+			return err
+		})
+	_ = AddOption([]string{"sync", "-a"}, 1, 0, 20, "a", "a", "", "",
+		func(str []string) error {
+			cancel()
+			return nil
+		}, nil)
+	_ = AddOption([]string{"sync", "-b"}, 1, 0, 10, "b", "b", "", "",
+		func(str []string) error {
+			fmt.Println("-b ran")
+			return nil
+		}, nil)
+
+	os.Args = []string{"fi", "sync", "-a", "-b"}
+	fmt.Println(ParseContext(ctx))
+
+	// Output:
+	// canceled: true
+	// context canceled
+}
+
+func ExampleEnableTimeout_args() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"exec"}, 1, -1, "run a command", "run a command", "", "[command...]",
+		func(str []string) error {
+			fmt.Println("exec", str[1:])
+			return nil
+		}, nil)
+	_ = AddOption([]string{"exec", "-env"}, 1, 1, 10, "environment", "environment", "", "[env]",
+		func(str []string) error {
+			fmt.Println("env", str[1])
+			return nil
+		}, nil)
+	EnableTimeout()
+	defer func() { TimeoutOption = "" }()
+
+	os.Args = []string{"fi", "exec", "--timeout", "1m", "-env", "--timeout", "curl", "--timeout", "5"}
+	fmt.Println(Parse())
+
+	// Output:
+	// env --timeout
+	// exec [curl --timeout 5]
+	// <nil>
+}
+
+func ExampleEnableTimeout_wait() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"sync"}, 1, 0, "sync files", "sync files", "", "",
+		func(str []string) error {
+			time.Sleep(20 * time.Millisecond)
+			fmt.Println("sync returned")
+			return nil
+		}, nil)
+	RootCommand.Commands["sync"].Timeout = time.Millisecond
+	RootCommand.Commands["sync"].PostRun = func(str []string) error {
+		fmt.Println("PostRun")
+		return nil
+	}
+
+	// Executor without context does not time out
+	os.Args = []string{"fi", "sync"}
+	fmt.Println(Parse())
+
+	// the deadline of the parent is not a timeout of the command
+	RootCommand.Commands["sync"].Timeout = time.Hour
+	RootCommand.Commands["sync"].ContextExecutor = func(ctx context.Context, str []string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	err := ParseContext(ctx)
+	fmt.Println(err, errors.Is(err, ErrTimeout))
+
+	// Output:
+	// sync returned
+	// PostRun
+	// <nil>
+	// PostRun
+	// context deadline exceeded false
+}
+
+func ExampleEnableDryRun_value() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"echo"}, 1, -1, "print arguments", "print arguments", "", "[text...]",
+		func(str []string) error {
+			fmt.Println("echo", str[1:])
+			return nil
+		}, nil)
+	_ = AddOption([]string{"echo", "-sep"}, 1, 1, 10, "separator", "separator", "", "[sep]",
+		func(str []string) error {
+			fmt.Println("sep", str[1])
+			return nil
+		}, nil)
+	EnableColor()
+	EnableDryRun()
+	defer func() {
+		ColorTheme = nil
+		DryRunOption = ""
+	}()
+
+	os.Args = []string{"fi", "echo", "-sep", "--dry-run", "a", "--dry-run", "--color=never"}
+	fmt.Println(Parse())
+
+	// Output:
+	// sep --dry-run
+	// echo [a --dry-run --color=never]
+	// <nil>
+}
+
+func ExampleTplNeedMoreArguments() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Err = os.Stdout
+	RootCommand.Size = 1
+	TplNeedMoreArguments = "%s [%s] wants %d arguments\n"
+	defer func() { TplNeedMoreArguments = "" }()
+
+	os.Args = []string{"fi"}
+	fmt.Println(Parse())
+
+	// Output:
+	// fi
+	//    ^
+	// command [fi] wants 1 arguments
+	// command [fi] wants 1 arguments
+}
+
+func ExampleFuncCompleter_combination() {
+	RootCommand = NewCommand("fi", "")
+	RootCommand.Size = 1
+	RootCommand.Completer = func(args []string, toComplete string) []string {
+		return []string{"file1", "file2"}
+	}
+	_ = AddOption([]string{"-a"}, 1, 0, 100, "all", "all", "", "", nil, nil)
+	_ = AddOption([]string{"-l"}, 1, 0, 100, "long", "long", "", "", nil, nil)
+	_ = AddOption([]string{"-o"}, 1, 1, 100, "output", "output", "", "[file]", nil, nil)
+	RootCommand.Options["-o"].Completer = func(args []string, toComplete string) []string {
+		return []string{"out.txt"}
+	}
+	EnableOptionCombination()
+	defer func() { OptionCombination = 0 }()
+
+	// "-al" is an option combination, not the positional argument
+	os.Args = []string{"fi", CompleteCommandName, "-al", "f"}
+	_ = Parse()
+	os.Args = []string{"fi", CompleteCommandName, "-al", "-o", ""}
+	_ = Parse()
+
+	// Output:
+	// file1
+	// file2
+	// out.txt
+}
+
+func ExampleCommand_GenerateMarkdownSingle() {
+	RootCommand = NewCommand("./fi", "")
+	_ = AddCommand([]string{"build"}, 1, 1, "build a file", "build a file", "", "[filename]",
+		func(str []string) error {
+			return nil
+		}, nil)
+
+	for _, v := range strings.Split(RootCommand.GenerateMarkdownSingle(), "\n") {
+		if strings.HasPrefix(v, "#") || strings.Contains(v, "](#") {
+			fmt.Println(v)
+		}
+	}
+
+	// Output:
+	// ## ./fi
+	// ### Synopsis
+	// ### Commands
+	// * [./fi build](#fi-build) - build a file
+	// ## ./fi build
+	// ### Synopsis
+	// ### See Also
+	// * [./fi](#fi)
+}
+
+func ExampleOption_Finally() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"deploy"}, 1, 0, "deploy", "deploy", "", "",
+		func(str []string) error {
+			fmt.Println("deploy")
+			return nil
+		}, nil)
+	_ = AddOption([]string{"deploy", "-lock"}, 1, 0, 20, "lock", "lock", "", "",
+		func(str []string) error {
+			fmt.Println("lock")
+			return nil
+		}, nil)
+	RootCommand.Commands["deploy"].Options["-lock"].Finally = func(str []string) error {
+		return errors.New("unlock failed")
+	}
+
+	os.Args = []string{"fi", "deploy", "-lock"}
+	fmt.Println(Parse())
+
+	// Output:
+	// lock
+	// deploy
+	// unlock failed
+}
+
+func ExampleParallel() {
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"serve"}, 1, 0, "serve", "serve", "", "", nil, nil)
+	_ = AddOption([]string{"serve", "-a"}, 1, 0, 10, "a", "a", "", "", nil, nil)
+	_ = AddOption([]string{"serve", "-b"}, 1, 0, 10, "b", "b", "", "",
+		func(str []string) error {
+			return errors.New("b: real failure")
+		}, nil)
+	RootCommand.Commands["serve"].Options["-a"].ContextExecutor = func(ctx context.Context, str []string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	Parallel = true
+	defer func() { Parallel = false }()
+
+	os.Args = []string{"fi", "serve", "-a", "-b"}
+	fmt.Println(Parse())
+
+	// Output:
+	// b: real failure
+}
+
+func ExampleCommand_Out() {
+	out := &bytes.Buffer{}
+	RootCommand = NewCommand("fi", "")
+	_ = AddCommand([]string{"build"}, 1, 0, "build a file", "build a file", "", "",
+		func(str []string) error {
+			return nil
+		}, nil)
+	// the tree is kept after the name of RootCommand is changed
+	RootCommand.Name = "fi2"
+	RootCommand.Out = out
+	RootCommand.PersistentPreRun = func(str []string) error {
+		fmt.Println("PersistentPreRun", str)
+		return nil
+	}
+	AddHelpCommandArg("help")
+	RootCommand.GenerateHelp()
+
+	os.Args = []string{"fi2", "build"}
+	_ = Parse()
+	os.Args = []string{"fi2", "help", "build"}
+	_ = Parse()
+	fmt.Println(out.Len() != 0)
+
+	// Output:
+	// PersistentPreRun [build]
+	// true
+}
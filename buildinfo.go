@@ -0,0 +1,20 @@
+//go:build go1.18
+// +build go1.18
+
+package arg
+
+import "runtime/debug"
+
+// Read the revision of version control from the build information
+func readVCSInfo(bi *debug.BuildInfo, info *VersionInfo) {
+	for _, v := range bi.Settings {
+		switch v.Key {
+		case "vcs.revision":
+			info.Revision = v.Value
+		case "vcs.time":
+			info.RevisionTime = v.Value
+		case "vcs.modified":
+			info.Modified = v.Value == "true"
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !go1.18
+// +build !go1.18
+
+package arg
+
+import "runtime/debug"
+
+// Read the revision of version control from the build information,
+// it is not recorded before go1.18
+func readVCSInfo(bi *debug.BuildInfo, info *VersionInfo) {
+}
@@ -0,0 +1,116 @@
+package arg
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// Theme of colorized output
+//
+// Each field is the parameters of ANSI SGR, like "1;36", empty for plain text
+type Theme struct {
+	Heading     string
+	Command     string
+	Option      string
+	Placeholder string
+	Error       string
+}
+
+var DefaultTheme = Theme{
+	Heading:     "1",
+	Command:     "36",
+	Option:      "32",
+	Placeholder: "33",
+	Error:       "31",
+}
+
+// Theme of help and error output, nil for plain text
+var ColorTheme *Theme
+
+const (
+	// Colorize if the output is a terminal and NO_COLOR is not set
+	ColorAuto = iota
+	ColorAlways
+	ColorNever
+)
+
+var ColorMode = ColorAuto
+
+// The option to set ColorMode, "--color=auto", "--color=always" or "--color=never"
+//
+// It is accepted after EnableColor, set it to "" to disable
+var ColorOption = "--color"
+
+// Colorize help and error output with DefaultTheme
+func EnableColor() {
+	theme := DefaultTheme
+	ColorTheme = &theme
+}
+
+// Whether the output on w is colorized
+func colorEnabled(w io.Writer) bool {
+	if ColorTheme == nil || ColorMode == ColorNever {
+		return false
+	}
+	if ColorMode == ColorAlways {
+		return true
+	}
+	if len(os.Getenv("NO_COLOR")) != 0 {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	_, ok = terminalWidth(f.Fd())
+	return ok
+}
+
+// Wrap s with ANSI SGR, the trailing new lines are kept outside
+func colorize(sgr, s string) string {
+	if len(sgr) == 0 {
+		return s
+	}
+	text := strings.TrimRight(s, "\n")
+	if len(text) == 0 {
+		return s
+	}
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m" + s[len(text):]
+}
+
+// Parse ColorOption at args[0] and set ColorMode, return the number of arguments used
+func parseColorOption(args []string) int {
+	if ColorTheme == nil || len(ColorOption) == 0 {
+		return 0
+	}
+	switch args[0] {
+	case ColorOption + "=auto":
+		ColorMode = ColorAuto
+	case ColorOption + "=always":
+		ColorMode = ColorAlways
+	case ColorOption + "=never":
+		ColorMode = ColorNever
+	default:
+		return 0
+	}
+	return 1
+}
+
+// Functions of the theme for help templates, they return the text as is if theme is nil
+//
+//	heading s      colorize s as a heading
+//	command s      colorize s as a command name
+//	option s       colorize s as an option name
+//	placeholder s  colorize s as an argument placeholder
+func themeFuncs(theme *Theme) map[string]interface{} {
+	if theme == nil {
+		theme = &Theme{}
+	}
+	return map[string]interface{}{
+		"heading":     func(s string) string { return colorize(theme.Heading, s) },
+		"command":     func(s string) string { return colorize(theme.Command, s) },
+		"option":      func(s string) string { return colorize(theme.Option, s) },
+		"placeholder": func(s string) string { return colorize(theme.Placeholder, s) },
+	}
+}
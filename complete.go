@@ -0,0 +1,103 @@
+package arg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The hidden command used by shell completion scripts, set it to "" to disable
+//
+//	prog __complete [arguments...] <partial argument>
+//
+// prints the candidates for the partial argument, one per line
+var CompleteCommandName = "__complete"
+
+// FuncCompleter returns the candidates for toComplete
+//
+// args are the arguments already given to the Command or Option
+type FuncCompleter func(args []string, toComplete string) []string
+
+// Print the candidates for the last element of args to the Out of cmd
+func printCompletion(cmd *Command, args []string) {
+	w := cmd.out()
+	for _, v := range complete(cmd, args) {
+		_, _ = fmt.Fprintln(w, v)
+	}
+}
+
+// Parsing for completion, nothing is executed or printed
+var completing = false
+
+// The Option whose arguments are being completed, and the arguments given to it
+var completeOption *Option
+var completeOptionArgs []string
+
+// A help argument is given, the names of commands and options are completed
+var completeHelp = false
+
+// Find the candidates for the last element of args, nothing is executed
+//
+// args are parsed by parse in completion mode, so the candidates follow what Parse accepts
+func complete(cmd *Command, args []string) []string {
+	toComplete := ""
+	if len(args) != 0 {
+		toComplete = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	queue = make(workQueue, 0)
+	command = cmd
+	commandArgs = []string{cmd.Name}
+	commandArgsIndex = []int{0}
+	arguments = append([]string{cmd.Name}, args...)
+	completing = true
+	completeOption, completeOptionArgs, completeHelp = nil, nil, false
+	defer func() {
+		completing = false
+		queue = make(workQueue, 0)
+	}()
+	_ = parse(cmd, args)
+
+	if opt := completeOption; opt != nil {
+		// toComplete is an argument of this option
+		if opt.Completer == nil {
+			return nil
+		}
+		return filterPrefix(opt.Completer(completeOptionArgs, toComplete), toComplete)
+	}
+
+	cmd = command
+	positional := commandArgs[1:]
+	candidates := make([]string, 0)
+	names := make([]string, 0, len(cmd.Commands))
+	for k, v := range cmd.Commands {
+		if !v.Hidden {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	candidates = append(candidates, names...)
+	names = make([]string, 0, len(cmd.Options))
+	for k, v := range cmd.Options {
+		if !v.Hidden {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	candidates = append(candidates, names...)
+	if !completeHelp && cmd.Completer != nil && (cmd.Size == -1 || len(positional) < cmd.Size) {
+		candidates = append(candidates, cmd.Completer(positional, toComplete)...)
+	}
+	return filterPrefix(candidates, toComplete)
+}
+
+// Keep the candidates which start with prefix
+func filterPrefix(candidates []string, prefix string) []string {
+	res := make([]string, 0, len(candidates))
+	for _, v := range candidates {
+		if strings.HasPrefix(v, prefix) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package arg
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type FuncContextExecutor func(context.Context, []string) error
+type FuncContextErrorHandler func(context.Context, error) error
+
+// Cancel the context of ParseContext on SIGINT or SIGTERM, exit with SignalExitCode on the second signal
+var HandleSignals = false
+
+// Exit code on the second signal
+var SignalExitCode = 130
+
+// Cleanup hooks run when the context of ParseContext is cancelled
+var cleanups = make([]func(), 0)
+
+// The context of the running ParseContext
+var parseCtx = context.Background()
+
+// OnCancel
+//
+// add a cleanup hook, run when the context of ParseContext is cancelled,
+// hooks added later run first
+func OnCancel(f func()) {
+	cleanups = append(cleanups, f)
+}
+
+// ParseContext
+//
+// Parse os.Args use RootCommand, ctx is passed to ContextExecutor and ContextErrorHandler.
+// It returns after the cleanup hooks are finished if ctx is cancelled
+func ParseContext(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+
+	if HandleSignals {
+		sig := make(chan os.Signal, 2)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+		go func() {
+			select {
+			case <-sig:
+				cancel()
+			case <-done:
+				return
+			}
+			select {
+			case <-sig:
+				os.Exit(SignalExitCode)
+			case <-done:
+			}
+		}()
+	}
+
+	cleaned := make(chan struct{})
+	go func() {
+		defer close(cleaned)
+		select {
+		case <-ctx.Done():
+		case <-done:
+			// cancelled when parsing finished
+			if ctx.Err() == nil {
+				return
+			}
+		}
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}()
+	defer func() {
+		close(done)
+		<-cleaned
+	}()
+
+	parseCtx = ctx
+	defer func() {
+		parseCtx = context.Background()
+	}()
+	return parseArgs(ctx)
+}
+
+// Executor of the Command with ctx, ContextExecutor first
+func (c *Command) executor(ctx context.Context) FuncExecutor {
+	if c.ContextExecutor != nil {
+		return func(args []string) error {
+			return c.ContextExecutor(ctx, args)
+		}
+	}
+	return c.Executor
+}
+
+// Error handler of the Command with ctx, ContextErrorHandler first
+func (c *Command) errorHandler(ctx context.Context) FuncErrorHandler {
+	if c.ContextErrorHandler != nil {
+		return func(err error) error {
+			return c.ContextErrorHandler(ctx, err)
+		}
+	}
+	return c.ErrorHandler
+}
+
+// Executor of the Option with ctx, ContextExecutor first
+func (o *Option) executor(ctx context.Context) FuncExecutor {
+	if o.ContextExecutor != nil {
+		return func(args []string) error {
+			return o.ContextExecutor(ctx, args)
+		}
+	}
+	return o.Executor
+}
+
+// Error handler of the Option with ctx, ContextErrorExecutor first
+func (o *Option) errorHandler(ctx context.Context) FuncErrorHandler {
+	if o.ContextErrorExecutor != nil {
+		return func(err error) error {
+			return o.ContextErrorExecutor(ctx, err)
+		}
+	}
+	return o.ErrorExecutor
+}
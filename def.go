@@ -2,172 +2,153 @@ package arg
 
 var Version = "Arg 1.0.0"
 
-var TplNeedMoreArguments = "The %s [%s] requires %d arguments to execute\n"
+// TplNeedMoreArguments overrides the message of MsgNeedMoreArguments in all locales if not empty,
+// like "The %s [%s] requires %d arguments to execute\n"
+//
+// Deprecated: use AddMessages with MsgNeedMoreArguments instead.
+var TplNeedMoreArguments = ""
 
+// Sprintf templates of help before HelpTemplate, they are not used any more.
+// Set HelpTemplate and OptionHelpTemplate, or Command.HelpTemplate and Option.HelpTemplate instead.
+
+// Deprecated: not used, see HelpTemplate.
 var TplCommandUsageSelf = "        %s %s"
+
+// Deprecated: not used, see HelpTemplate.
 var TplCommandUsageCommand = "        %s <command> [arguments]\n"
+
+// Deprecated: not used, see HelpTemplate.
 var TplCommandUsageOption = "        %s <option>  [arguments]\n"
 
-// TplHelp =====================================================
-/*
+// Deprecated: not used, see HelpTemplate.
+var TplHelp = `
+%s
 
-Arg
+%s%s%s
+`
 
-  Arg is a flag parser
+// Deprecated: not used, see HelpTemplate.
+var TplDescribeUp = "%s"
 
-Usage:
+// Deprecated: not used, see HelpTemplate.
+var TplDescribeDown = "\n\n    %s"
 
-		arg [filename]
-		arg <command> [arguments]
-		arg <option>  [arguments]
+// Deprecated: not used, see HelpTemplate.
+var TplUsage = `Usage:
 
-The commands are
+%s`
 
-		build    build a programme
-		encrypt  encrypt a file
+// Deprecated: not used, see HelpTemplate.
+var HTplCommandList = `
+The commands are:
 
-Usage "arg help <command>" for more information about a command
+%s
+Use "%s %s <command>" for more information about a command.
+`
 
-The options are
+// Deprecated: not used, see HelpTemplate.
+var HTplOptionList = `
+The options are:
 
-		-o  [filename]
-			  Specify out file
-		-e  [password]
-			  The password for file
+%s
+Use "%s %s <option>" for more information about a option.
+`
 
-Usage "arg help <option>" for more information about a option
+// Deprecated: not used, see OptionHelpTemplate.
+var HTplOptionUsage = `
+Usage: %s
 
-===========================================================================
+%s
+`
 
-TplDescribe Begin ---
+// Deprecated: not used, see HelpTemplate.
+var HTplLineCommand = `        %%-%ds  %%s
+`
 
-TplDescribe End ---
+// Deprecated: not used, see HelpTemplate.
+var HTplLineOption = `        %%-%ds  %%s
+        %%%ds    %%s
+`
 
-TplUsage Begin ---
+// TplDiagnostic ======================================================
+/*
+fi build -tpye file
+         ^^^^^
+unknown option "-tpye" for "fi build"
+*/
+var TplDiagnostic = "%s\n%s\n%s\n"
 
-	# TplCommandUsageSelf Begin ---
-	# TplCommandUsageSelf End ---
-	# TplCommandUsageCommand Begin ---
-	# TplCommandUsageCommand End ---
-	# TplCommandUsageOption Begin ---
-	# TplCommandUsageOption End ---
+// HelpTemplate ======================================================
+/*
 
-TplUsage End ---
+fi
 
-HTplCommandList Begin ---
+    this is describe
 
-	# HTplLineCommand Begin ---
-	# HTplLineCommand End ---
-	# HTplLineCommand Begin ---
-	# HTplLineCommand End ---
+Usage:
 
-HTplCommandList End ---
+        fi [filename]
+        fi <command> [arguments]
+        fi <option>  [arguments]
 
-HTplOptionList Begin ---
+The commands are:
 
-	# HTplLineOption Begin ---
-	# HTplLineOption End ---
-	# HTplLineOption Begin ---
-	# HTplLineOption End ---
+        build    build a programme
+        encrypt  encrypt a file
 
-HTplOptionList End ---
-*/
-var TplHelp = `
-%s
+Use "fi help <command>" for more information about a command.
 
-%s%s%s
-`
-
-// TplDescribe =====================================================
-/*
-Arg
-	Arg is a arguments parser
-*/
-//var TplDescribe = "%s\n\n    %s"
-var TplDescribeUp = "%s"
-var TplDescribeDown = "\n\n    %s"
+The options are:
 
-// TplUsage =====================================================
-/*
-Usage:
-		arg <command> [arguments]
-*/
-var TplUsage = `Usage:
+        -o  [filename]
+              Specify out file
+        -e  [password]
+              The password for file
 
-%s`
+Output options:
 
-// HTplCommandList ======================================================
-/*
-The commands are:
+        -v
+              print details
 
-        build      build a programme
-        encrypt    encrypt a file
+Use "fi help <option>" for more information about a option.
 
-Use "%s help <command>" for more information about a command.
+The template is executed with HelpView, functions are in HelpFuncs
 */
-var HTplCommandList = `
-The commands are:
+var HelpTemplate = `
+{{heading .FullName}}{{with trim .Describe}}
 
-%s
-Use "%s %s <command>" for more information about a command.
-`
+{{indent 4 (wrap (sub $.Width 4) .)}}{{end}}
 
-// HTplOptionList ======================================================
-/*
-The options are:
+{{heading (tr "usage")}}
 
-        -o [filename]
-                Specify out file
-        -e [password]
-                Encrypt File
+{{range .UsageLines}}        {{.}}
+{{end}}{{if .Commands}}{{$w := nameWidth .Commands}}{{range .CommandGroups}}
+{{if .Title}}{{heading (printf "%s:" (text .Title))}}{{else}}{{heading (tr "commands")}}{{end}}
 
-Use "%s help <option>" for more information about a command.
-*/
-var HTplOptionList = `
-The options are:
+{{range .Entries}}        {{command (pad $w .Name)}}  {{hang (add $w 10) (wrap (sub $.Width (add $w 10)) (brief .))}}
+{{end}}{{end}}
+{{tr "more-command" .FullName .HelpArgs}}
+{{end}}{{if .Options}}{{$w := nameWidth .Options}}{{range .OptionGroups}}
+{{if .Title}}{{heading (printf "%s:" (text .Title))}}{{else}}{{heading (tr "options")}}{{end}}
 
-%s
-Use "%s %s <option>" for more information about a option.
+{{range .Entries}}        {{option (pad $w .Name)}}{{with .Usage}}  {{placeholder .}}{{end}}
+{{indent (add $w 12) (wrap (sub $.Width (add $w 12)) (brief .))}}
+{{end}}{{end}}
+{{tr "more-option" .FullName .HelpArgs}}
+{{end}}
 `
 
-// HTplOptionUsage ======================================================
+// OptionHelpTemplate ======================================================
 /*
-Usage: Arg -build [-o file.tgz]
-#Usage: father name usage
 
-build file to file.tgz
-#describe
-*/
-var HTplOptionUsage = `
-Usage: %s
+Usage: fi -build [-o file.tgz]
 
-%s
-`
+build file to file.tgz
 
-// HTplLineCommand ======================================================
-/*
-		%%-%ds  %%s
-		%-8s    %s
-		build   build to tgz
-#       name    describeBrief
+The template is executed with OptionHelpView, functions are in HelpFuncs
 */
-var HTplLineCommand = `        %%-%ds  %%s
-`
+var OptionHelpTemplate = `
+{{heading (tr "usage")}} {{option .FullName}}{{with .Usage}} {{placeholder .}}{{end}}
 
-// HTplLineOption ======================================================
-/*
-	%%-%ds  %%s
-		%%s
--------------------
-	%-8s    %s
-		%s
--------------------
-	-o      [filename]
-		Specify out file
---------------------
-    name    usage
-        describeBrief
-*/
-var HTplLineOption = `        %%-%ds  %%s
-        %%%ds    %%s
+{{wrap .Width .Describe}}
 `
@@ -0,0 +1,192 @@
+package arg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// Order the works by Option.After and Option.Before, then by their order in q
+//
+// The works are sorted topologically, the first work in q is taken when several are ready.
+// The works in a cycle are kept in order, use Check to find cycles
+func (q *workQueue) order() {
+	n := len(*q)
+	next := make([][]int, n)
+	degree := make([]int, n)
+	edge := func(from, to int) {
+		next[from] = append(next[from], to)
+		degree[to]++
+	}
+	for i, w := range *q {
+		if w.Option == nil {
+			continue
+		}
+		for j, v := range *q {
+			if v.Option == nil || i == j {
+				continue
+			}
+			if contains(w.Option.After, v.Option.Name) {
+				edge(j, i)
+			}
+			if contains(w.Option.Before, v.Option.Name) {
+				edge(i, j)
+			}
+		}
+	}
+
+	res := make(workQueue, 0, n)
+	done := make([]bool, n)
+	for len(res) < n {
+		k := -1
+		for i := range *q {
+			if !done[i] && degree[i] == 0 {
+				k = i
+				break
+			}
+		}
+		if k == -1 {
+			// cycle, take the first one left
+			for i := range *q {
+				if !done[i] {
+					k = i
+					break
+				}
+			}
+		}
+		done[k] = true
+		res = append(res, (*q)[k])
+		for _, v := range next[k] {
+			degree[v]--
+		}
+	}
+	*q = res
+}
+
+// Run the Option after the options of names, if they are given
+//
+// The options of names must be added before, the relations are checked with the Command,
+// and not changed if the check failed
+func (o *Option) RunAfter(names ...string) error {
+	after := o.After
+	o.After = append(append(make([]string, 0), o.After...), names...)
+	if err := o.command().checkOptions(); err != nil {
+		o.After = after
+		return err
+	}
+	return nil
+}
+
+// Run the Option before the options of names, if they are given
+//
+// The options of names must be added before, the relations are checked with the Command,
+// and not changed if the check failed
+func (o *Option) RunBefore(names ...string) error {
+	before := o.Before
+	o.Before = append(append(make([]string, 0), o.Before...), names...)
+	if err := o.command().checkOptions(); err != nil {
+		o.Before = before
+		return err
+	}
+	return nil
+}
+
+// Check
+//
+// check Option.After and Option.Before of the Command and its sub commands,
+// the names must be options of the same command, and must not form a cycle.
+//
+// RunAfter and RunBefore check the relations when they are added, call Check after the tree
+// is built if After and Before are set directly. Parse calls it on RootCommand
+func (c *Command) Check() error {
+	if err := c.checkOptions(); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(c.Commands))
+	for k := range c.Commands {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, v := range names {
+		if err := c.Commands[v].Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check Option.After and Option.Before of the options of the Command
+func (c *Command) checkOptions() error {
+	names := make([]string, 0, len(c.Options))
+	for k := range c.Options {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	// options which must run after the option
+	next := make(map[string][]string)
+	for _, name := range names {
+		opt := c.Options[name]
+		for _, v := range opt.After {
+			if _, ok := c.Options[v]; !ok {
+				return fmt.Errorf("%w %q in After of %q", ErrUnknownOption, v, opt.FullName())
+			}
+			next[v] = append(next[v], name)
+		}
+		for _, v := range opt.Before {
+			if _, ok := c.Options[v]; !ok {
+				return fmt.Errorf("%w %q in Before of %q", ErrUnknownOption, v, opt.FullName())
+			}
+			next[name] = append(next[name], v)
+		}
+	}
+
+	// 0: not visited, 1: visiting, 2: visited
+	state := make(map[string]int)
+	path := make([]string, 0)
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			for k, v := range path {
+				if v == name {
+					path = append(path[k:], name)
+					break
+				}
+			}
+			return fmt.Errorf("%w in %q: %s", ErrDependencyCycle, c.FullName(), strings.Join(path, " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		path = append(path, name)
+		for _, v := range next[name] {
+			if err := visit(v); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = 2
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Whether list contains s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
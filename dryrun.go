@@ -0,0 +1,111 @@
+package arg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDryRun = errors.New("dry run")
+
+// Name of the built-in option to print the plan instead of executing, like "--dry-run" or "--dry-run=json",
+// disabled if empty
+var DryRunOption = ""
+
+// Format of the plan in dry-run mode, "" if it is not in dry-run mode, "text" or "json"
+var dryRun = ""
+
+// Plan is what would be executed
+type Plan struct {
+	// Full name of the Command, like "go mod download"
+	Command string `json:"command"`
+	// Options in the order of execution
+	Options []PlanOption `json:"options"`
+	// Arguments of the Command
+	Args []string `json:"args"`
+}
+
+// PlanOption is an Option would be executed
+type PlanOption struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+// TplPlan ======================================================
+/*
+command: fi build
+options:
+    -type tgz
+    -del
+args: file1 file2
+*/
+var TplPlanCommand = "command: %s\n"
+var TplPlanOptions = "options:\n"
+var TplPlanOption = "    %s\n"
+var TplPlanArgs = "args: %s\n"
+
+// Add the built-in option "--dry-run" to all commands
+//
+// With it, the plan is printed and Parse returns ErrDryRun, executors are not run
+// unless the Command is Simulate
+func EnableDryRun() {
+	DryRunOption = "--dry-run"
+}
+
+// Whether it is in dry-run mode, executors of a Simulate command should not make changes
+func IsDryRun() bool {
+	return len(dryRun) != 0
+}
+
+// Parse DryRunOption at args[0] and set dryRun, return the number of arguments used
+func parseDryRunOption(args []string) int {
+	if len(DryRunOption) == 0 {
+		return 0
+	}
+	switch args[0] {
+	case DryRunOption, DryRunOption + "=text":
+		dryRun = "text"
+	case DryRunOption + "=json":
+		dryRun = "json"
+	default:
+		return 0
+	}
+	return 1
+}
+
+// The plan of the Command with the sorted queue and args
+func newPlan(cmd *Command, q workQueue, args []string) Plan {
+	plan := Plan{
+		Command: cmd.FullName(),
+		Options: make([]PlanOption, 0, len(q)),
+		Args:    append(make([]string, 0), args[1:]...),
+	}
+	for _, v := range q {
+		plan.Options = append(plan.Options, PlanOption{
+			Name: v.Args[0],
+			Args: append(make([]string, 0), v.Args[1:]...),
+		})
+	}
+	return plan
+}
+
+// Print the plan in the format of dryRun to the Out of cmd
+func printPlan(cmd *Command, plan Plan) {
+	w := cmd.out()
+	if dryRun == "json" {
+		data, _ := json.MarshalIndent(plan, "", "  ")
+		_, _ = fmt.Fprintln(w, string(data))
+		return
+	}
+	_, _ = fmt.Fprintf(w, TplPlanCommand, plan.Command)
+	if len(plan.Options) != 0 {
+		_, _ = fmt.Fprint(w, TplPlanOptions)
+		for _, v := range plan.Options {
+			_, _ = fmt.Fprintf(w, TplPlanOption, strings.Join(append([]string{v.Name}, v.Args...), " "))
+		}
+	}
+	if len(plan.Args) != 0 {
+		_, _ = fmt.Fprintf(w, TplPlanArgs, strings.Join(plan.Args, " "))
+	}
+}
@@ -1,7 +1,173 @@
 package arg
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 var ErrWrongArgPath = errors.New("wrong arg path")
 var ErrNeedMoreArguments = errors.New("wrong number of arg")
 var ErrHelp = errors.New("help")
+var ErrComplete = errors.New("complete")
+var ErrUnknownCommand = errors.New("unknown command")
+var ErrUnknownOption = errors.New("unknown option")
+var ErrTimeout = errors.New("timeout")
+
+// ParseError is an error of the arguments
+//
+// It wraps ErrNeedMoreArguments, ErrUnknownCommand, ErrUnknownOption or ErrWrongArgPath,
+// use errors.Is to check it
+type ParseError struct {
+	Err error
+	// The arguments being parsed, Args[0] is the programme
+	// For ErrWrongArgPath, it is the path passed to Add
+	Args []string
+	// The offending argument and its index in Args
+	// Index is len(Args) if the argument is missing
+	Token string
+	Index int
+	// Full name of the Command, like "go mod"
+	Command string
+	// Name of the Option, empty if the error is not about an option
+	Option string
+	// Number of arguments required and given, for ErrNeedMoreArguments
+	Expected int
+	Actual   int
+	// Names close to Token, for ErrUnknownCommand and ErrUnknownOption
+	Suggestions []string
+}
+
+func (e *ParseError) Error() string {
+	msg := ""
+	kind := MsgUnknownCommand
+	if errors.Is(e.Err, ErrUnknownOption) {
+		kind = MsgUnknownOption
+	}
+	switch {
+	case errors.Is(e.Err, ErrNeedMoreArguments) && len(e.Option) != 0:
+		msg = fmt.Sprintf(needMoreArguments(), T(MsgOption), e.Command+" "+e.Option, e.Expected)
+	case errors.Is(e.Err, ErrNeedMoreArguments):
+		msg = fmt.Sprintf(needMoreArguments(), T(MsgCommand), e.Command, e.Expected)
+	case (errors.Is(e.Err, ErrUnknownCommand) || errors.Is(e.Err, ErrUnknownOption)) && len(e.Suggestions) != 0:
+		list := ""
+		for _, v := range e.Suggestions {
+			list += "\t" + v + "\n"
+		}
+		msg = Tf(MsgSuggestion, T(kind), e.Token, e.Command, list)
+	case errors.Is(e.Err, ErrUnknownCommand) || errors.Is(e.Err, ErrUnknownOption):
+		msg = Tf(MsgUnknown, T(kind), e.Token, e.Command)
+	case errors.Is(e.Err, ErrWrongArgPath):
+		msg = Tf(MsgWrongArgPath, e.Token, e.Command)
+	default:
+		msg = e.Err.Error()
+	}
+	return strings.TrimRight(msg, "\n")
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic is the command line with a caret under the offending argument, and the error
+//
+//	fi build -tpye file
+//	         ^^^^^
+//	unknown option "-tpye" for "fi build"
+func (e *ParseError) Diagnostic() string {
+	line := ""
+	caret := 0
+	width := 1
+	for k, v := range e.Args {
+		if k != 0 {
+			line += " "
+		}
+		v = quoteArg(v)
+		if k == e.Index {
+			caret = stringWidth(line)
+			if w := stringWidth(v); w > 1 {
+				width = w
+			}
+		}
+		line += v
+	}
+	if e.Index >= len(e.Args) {
+		caret = stringWidth(line) + 1
+	}
+	return fmt.Sprintf(TplDiagnostic, line, strings.Repeat(" ", caret)+strings.Repeat("^", width), e.Error())
+}
+
+// Quote the argument if it is empty or has white space
+func quoteArg(s string) string {
+	if len(s) == 0 || strings.ContainsAny(s, " \t\n\"'") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Template of ErrNeedMoreArguments, TplNeedMoreArguments if it is set
+func needMoreArguments() string {
+	if len(TplNeedMoreArguments) != 0 {
+		return TplNeedMoreArguments
+	}
+	return T(MsgNeedMoreArguments)
+}
+
+// ExecutorPanicError is a panic in an executor, recovered if RecoverPanics is true
+type ExecutorPanicError struct {
+	// The value passed to panic
+	Value interface{}
+	// Stack trace of the goroutine which panicked
+	Stack []byte
+	// Full name of the Command, like "go mod"
+	Command string
+	// Name of the Option, empty if it is the Executor of the Command
+	Option string
+}
+
+func (e *ExecutorPanicError) Error() string {
+	if len(e.Option) != 0 {
+		return Tf(MsgPanic, T(MsgOption), e.Command+" "+e.Option, e.Value)
+	}
+	return Tf(MsgPanic, T(MsgCommand), e.Command, e.Value)
+}
+
+// The value passed to panic if it is an error
+func (e *ExecutorPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// MultiError is the errors of the options executed with ContinueOnError, in order of execution
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msg := make([]string, 0, len(e))
+	for _, v := range e {
+		msg = append(msg, v.Error())
+	}
+	return strings.Join(msg, "\n")
+}
+
+// Whether any of the errors matches target
+func (e MultiError) Is(target error) bool {
+	for _, v := range e {
+		if errors.Is(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the first error that matches target, and set target to it
+func (e MultiError) As(target interface{}) bool {
+	for _, v := range e {
+		if errors.As(v, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,314 @@
+package arg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// HelpEntry is a Command or Option in help
+type HelpEntry struct {
+	Name          string
+	FullName      string
+	Usage         string
+	Describe      string
+	DescribeBrief string
+	// Deprecation message, empty if it is not deprecated
+	Deprecated string
+}
+
+// Width of help when the width of the terminal is unknown
+var HelpWidth = 80
+
+// HelpView is the Command passed to HelpTemplate
+type HelpView struct {
+	HelpEntry
+	// Width of the terminal
+	Width int
+	// Usage lines, like "fi <command> [arguments]"
+	UsageLines []string
+	// Sub commands and options, sorted by Order
+	Commands []HelpEntry
+	Options  []HelpEntry
+	// Sub commands and options in groups, the group without Title first
+	CommandGroups []HelpGroup
+	OptionGroups  []HelpGroup
+	// The help arguments joined by "/", like "help"
+	HelpArgs string
+}
+
+// HelpGroup is a section of commands or options in help
+type HelpGroup struct {
+	// Title of the section, empty for the commands and options without Group
+	Title   string
+	Entries []HelpEntry
+}
+
+// Order of groups in help, the groups not in it are sorted by title after them
+//
+// Command.GroupOrder overrides it for a command
+var GroupOrder []string
+
+// OptionHelpView is the Option passed to OptionHelpTemplate
+type OptionHelpView struct {
+	HelpEntry
+	// Width of the terminal
+	Width int
+}
+
+// Functions for HelpTemplate and OptionHelpTemplate
+//
+// The functions of the ColorTheme, heading, command, option and placeholder, are available too
+//
+//	pad n s        fill s with spaces to n columns
+//	width s        columns taken by s in the terminal
+//	indent n s     prefix each line of s with n spaces
+//	hang n s       prefix each line of s except the first with n spaces
+//	wrap n s       wrap each line of s at n columns
+//	add a b        a + b
+//	sub a b        a - b
+//	trim s         remove leading and trailing white space
+//	join sep list  join list with sep
+//	nameWidth list the widest Name in list of HelpEntry
+//	brief entry    DescribeBrief of the HelpEntry, marked if it is deprecated
+//	tr id args...  translate the message of id and format it with args
+//	text s         translate the text of the application
+var HelpFuncs = template.FuncMap{
+	"pad":       pad,
+	"width":     stringWidth,
+	"indent":    indent,
+	"hang":      hang,
+	"wrap":      wrap,
+	"add":       func(a, b int) int { return a + b },
+	"sub":       func(a, b int) int { return a - b },
+	"trim":      strings.TrimSpace,
+	"join":      func(sep string, list []string) string { return strings.Join(list, sep) },
+	"nameWidth": nameWidth,
+	"brief":     brief,
+	"tr":        Tf,
+	"text":      Translate,
+}
+
+// Execute the help template with data, the error is returned as help
+//
+// The theme functions colorize text with theme, or return it as is if theme is nil
+func renderHelp(tpl string, data interface{}, theme *Theme) string {
+	t, err := template.New("help").Funcs(themeFuncs(theme)).Funcs(HelpFuncs).Parse(tpl)
+	if err != nil {
+		return err.Error() + "\n"
+	}
+	buf := &bytes.Buffer{}
+	if err = t.Execute(buf, data); err != nil {
+		return err.Error() + "\n"
+	}
+	return buf.String()
+}
+
+// The view of the Command for HelpTemplate
+func (c *Command) helpView() HelpView {
+	fullName := c.FullName()
+	view := HelpView{
+		HelpEntry: c.helpEntry(),
+		Width:     helpWidth(c.out()),
+	}
+	if c.runnable() {
+		view.UsageLines = append(view.UsageLines, strings.TrimRight(fullName+" "+c.Usage, " "))
+	}
+	commandGroups := make(map[string][]HelpEntry)
+	for _, v := range c.visibleCommands() {
+		view.Commands = append(view.Commands, v.helpEntry())
+		commandGroups[v.Group] = append(commandGroups[v.Group], v.helpEntry())
+	}
+	optionGroups := make(map[string][]HelpEntry)
+	for _, v := range c.visibleOptions() {
+		view.Options = append(view.Options, v.helpEntry())
+		optionGroups[v.Group] = append(optionGroups[v.Group], v.helpEntry())
+	}
+	order := c.GroupOrder
+	if order == nil {
+		order = GroupOrder
+	}
+	view.CommandGroups = helpGroups(commandGroups, order)
+	view.OptionGroups = helpGroups(optionGroups, order)
+	if len(view.Commands) != 0 {
+		view.UsageLines = append(view.UsageLines, fullName+" <command> [arguments]")
+	}
+	if len(view.Options) != 0 {
+		view.UsageLines = append(view.UsageLines, fullName+" <option>  [arguments]")
+	}
+	helpArgs := make([]string, 0, len(HelpCommandArgs))
+	for k := range HelpCommandArgs {
+		helpArgs = append(helpArgs, k)
+	}
+	sort.Strings(helpArgs)
+	view.HelpArgs = strings.Join(helpArgs, "/")
+	return view
+}
+
+// Sort groups by order, the group without title first
+func helpGroups(groups map[string][]HelpEntry, order []string) []HelpGroup {
+	titles := make([]string, 0, len(groups))
+	for k := range groups {
+		titles = append(titles, k)
+	}
+	rank := make(map[string]int)
+	for k, v := range order {
+		if _, ok := rank[v]; !ok {
+			rank[v] = k + 1
+		}
+	}
+	index := func(title string) int {
+		if len(title) == 0 {
+			return 0
+		}
+		if r, ok := rank[title]; ok {
+			return r
+		}
+		return len(order) + 1
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if index(titles[i]) != index(titles[j]) {
+			return index(titles[i]) < index(titles[j])
+		}
+		return titles[i] < titles[j]
+	})
+	res := make([]HelpGroup, 0, len(titles))
+	for _, v := range titles {
+		res = append(res, HelpGroup{Title: v, Entries: groups[v]})
+	}
+	return res
+}
+
+// The Command in help
+func (c *Command) helpEntry() HelpEntry {
+	return HelpEntry{
+		Name:          c.Name,
+		FullName:      c.FullName(),
+		Usage:         c.Usage,
+		Describe:      Translate(c.Describe),
+		DescribeBrief: Translate(c.DescribeBrief),
+		Deprecated:    c.Deprecated,
+	}
+}
+
+// The Option in help
+func (o *Option) helpEntry() HelpEntry {
+	return HelpEntry{
+		Name:          o.Name,
+		FullName:      o.FullName(),
+		Usage:         o.Usage,
+		Describe:      Translate(o.Describe),
+		DescribeBrief: Translate(o.DescribeBrief),
+		Deprecated:    o.Deprecated,
+	}
+}
+
+// Width of the terminal
+//
+// COLUMNS first, then the size of the terminal on out, HelpWidth if both are unknown
+func helpWidth(out io.Writer) int {
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	if f, ok := out.(*os.File); ok {
+		if w, ok := terminalWidth(f.Fd()); ok && w > 0 {
+			return w
+		}
+	}
+	return HelpWidth
+}
+
+// Fill s with spaces to n columns
+func pad(n int, s string) string {
+	if l := stringWidth(s); l < n {
+		return s + strings.Repeat(" ", n-l)
+	}
+	return s
+}
+
+// Prefix each non-empty line of s with n spaces
+func indent(n int, s string) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for k, v := range lines {
+		if len(v) != 0 {
+			lines[k] = prefix + v
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Prefix each non-empty line of s except the first with n spaces
+func hang(n int, s string) string {
+	if i := strings.Index(s, "\n"); i != -1 {
+		return s[:i+1] + indent(n, s[i+1:])
+	}
+	return s
+}
+
+// Wrap each line of s at n columns, n <= 0 means no wrapping
+//
+// Blank lines are kept, lines start with white space are preformatted and kept
+func wrap(n int, s string) string {
+	if n <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	res := make([]string, 0, len(lines))
+	for _, v := range lines {
+		if strings.HasPrefix(v, " ") || strings.HasPrefix(v, "\t") {
+			res = append(res, v)
+			continue
+		}
+		line := ""
+		lineWidth := 0
+		for _, word := range strings.Fields(v) {
+			wordWidth := stringWidth(word)
+			if lineWidth != 0 && lineWidth+1+wordWidth > n {
+				res = append(res, line)
+				line = ""
+				lineWidth = 0
+			}
+			if lineWidth != 0 {
+				line += " "
+				lineWidth++
+			}
+			// Words wider than n, like a sentence without spaces in CJK, are broken at any rune
+			for _, r := range word {
+				rw := runeWidth(r)
+				if lineWidth != 0 && lineWidth+rw > n && rw != 0 {
+					res = append(res, line)
+					line = ""
+					lineWidth = 0
+				}
+				line += string(r)
+				lineWidth += rw
+			}
+		}
+		res = append(res, line)
+	}
+	return strings.Join(res, "\n")
+}
+
+// DescribeBrief of the entry, marked if it is deprecated
+func brief(entry HelpEntry) string {
+	if len(entry.Deprecated) == 0 {
+		return entry.DescribeBrief
+	}
+	return strings.TrimLeft(entry.DescribeBrief+" "+Tf(MsgDeprecatedMark, entry.Deprecated), " ")
+}
+
+// The widest Name in list
+func nameWidth(list []HelpEntry) int {
+	w := 0
+	for _, v := range list {
+		if l := stringWidth(v.Name); l > w {
+			w = l
+		}
+	}
+	return w
+}
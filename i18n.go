@@ -0,0 +1,154 @@
+package arg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Messages is a bundle of translations, keyed by message ID
+type Messages map[string]string
+
+// IDs of the messages of Arg
+const (
+	MsgCommand           = "command"
+	MsgOption            = "option"
+	MsgNeedMoreArguments = "need-more-arguments"
+	MsgUnknownCommand    = "unknown-command"
+	MsgUnknownOption     = "unknown-option"
+	MsgUnknown           = "unknown"
+	MsgSuggestion        = "suggestion"
+	MsgWrongArgPath      = "wrong-arg-path"
+	MsgDeprecated        = "deprecated"
+	MsgDeprecatedMark    = "deprecated-mark"
+	MsgUsage             = "usage"
+	MsgCommands          = "commands"
+	MsgOptions           = "options"
+	MsgMoreCommand       = "more-command"
+	MsgMoreOption        = "more-option"
+	MsgPanic             = "panic"
+)
+
+// Catalog of messages of Arg, keyed by locale, like "en", "zh" or "zh_TW"
+var Catalog = map[string]Messages{
+	"en": {
+		MsgCommand:           "command",
+		MsgOption:            "option",
+		MsgNeedMoreArguments: "The %s [%s] requires %d arguments to execute",
+		MsgUnknownCommand:    "unknown command",
+		MsgUnknownOption:     "unknown option",
+		MsgUnknown:           "%s %q for %q",
+		MsgSuggestion:        "%s %q for %q\n\nDid you mean this?\n%s",
+		MsgWrongArgPath:      "wrong arg path: %q is not a command of %q",
+		MsgDeprecated:        "The %s [%s] is deprecated, %s",
+		MsgDeprecatedMark:    "(deprecated: %s)",
+		MsgUsage:             "Usage:",
+		MsgCommands:          "The commands are:",
+		MsgOptions:           "The options are:",
+		MsgMoreCommand:       "Use \"%s %s <command>\" for more information about a command.",
+		MsgMoreOption:        "Use \"%s %s <option>\" for more information about a option.",
+		MsgPanic:             "panic in %s [%s]: %v",
+	},
+	"zh": {
+		MsgCommand:           "命令",
+		MsgOption:            "选项",
+		MsgNeedMoreArguments: "%s [%s] 需要 %d 个参数",
+		MsgUnknownCommand:    "未知命令",
+		MsgUnknownOption:     "未知选项",
+		MsgUnknown:           "%s %q，位于 %q",
+		MsgSuggestion:        "%s %q，位于 %q\n\n您是不是要找：\n%s",
+		MsgWrongArgPath:      "错误的参数路径：%q 不是 %q 的命令",
+		MsgDeprecated:        "%s [%s] 已弃用，%s",
+		MsgDeprecatedMark:    "（已弃用：%s）",
+		MsgUsage:             "用法：",
+		MsgCommands:          "命令：",
+		MsgOptions:           "选项：",
+		MsgMoreCommand:       "使用 \"%s %s <命令>\" 查看命令的详细信息。",
+		MsgMoreOption:        "使用 \"%s %s <选项>\" 查看选项的详细信息。",
+		MsgPanic:             "%s [%s] 发生 panic：%v",
+	},
+}
+
+// Translations of the text of the application, like Describe, DescribeBrief and Group,
+// keyed by locale, then by the text itself
+//
+// They are apart from Catalog, so the text never clashes with the message IDs
+var Translations = make(map[string]Messages)
+
+// Locale of messages, like "zh_CN", from LC_ALL, LC_MESSAGES or LANG if empty
+var Locale = ""
+
+// Add translations of the messages of Arg to the Catalog
+func AddMessages(locale string, messages Messages) {
+	addMessages(Catalog, locale, messages)
+}
+
+// Add translations of the text of the application to Translations
+func AddTranslations(locale string, translations Messages) {
+	addMessages(Translations, locale, translations)
+}
+
+// Translate the message of Arg
+//
+// For locale "zh_CN.UTF-8", "zh_CN", "zh" and "en" are searched in order,
+// id itself is returned if it is not found
+func T(id string) string {
+	return lookup(Catalog, id)
+}
+
+// Translate the text of the application, text itself is returned if it is not found
+func Translate(text string) string {
+	return lookup(Translations, text)
+}
+
+// Add messages of locale to catalog
+func addMessages(catalog map[string]Messages, locale string, messages Messages) {
+	if catalog[locale] == nil {
+		catalog[locale] = make(Messages)
+	}
+	for k, v := range messages {
+		catalog[locale][k] = v
+	}
+}
+
+// Find id in catalog by locales
+func lookup(catalog map[string]Messages, id string) string {
+	if len(id) == 0 {
+		return id
+	}
+	for _, v := range locales() {
+		if msg, ok := catalog[v][id]; ok {
+			return msg
+		}
+	}
+	return id
+}
+
+// Translate the message and format it with a
+func Tf(id string, a ...interface{}) string {
+	return fmt.Sprintf(T(id), a...)
+}
+
+// The locales to search, like "zh_CN", "zh" and "en"
+func locales() []string {
+	locale := Locale
+	if len(locale) == 0 {
+		for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if locale = os.Getenv(v); len(locale) != 0 {
+				break
+			}
+		}
+	}
+	// remove encoding and modifier, like ".UTF-8" and "@euro"
+	if i := strings.IndexAny(locale, ".@"); i != -1 {
+		locale = locale[:i]
+	}
+	res := make([]string, 0, 3)
+	if len(locale) != 0 && locale != "C" && locale != "POSIX" {
+		res = append(res, locale)
+		if i := strings.IndexAny(locale, "_-"); i != -1 {
+			res = append(res, locale[:i])
+		}
+	}
+	return append(res, "en")
+}
@@ -0,0 +1,144 @@
+package arg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Section of the generated man pages
+var ManSection = "1"
+
+// Date in the header of the man pages, current month if empty
+var ManDate = ""
+
+// Source and Manual in the header of the man pages
+var ManSource = ""
+var ManManual = ""
+
+// TplManHeader ======================================================
+/*
+.TH "FI-VERSION" "1" "October 2026" "fi 1.0" "fi Manual"
+#   name         section  date      source   manual
+*/
+var TplManHeader = ".TH \"%s\" \"%s\" \"%s\" \"%s\" \"%s\"\n"
+
+// TplManName ======================================================
+/*
+.SH NAME
+fi\-version \- check programme version
+*/
+var TplManName = ".SH NAME\n%s \\- %s\n"
+
+var TplManSynopsis = ".SH SYNOPSIS\n%s"
+var TplManSynopsisLine = ".B %s\n%s.br\n"
+var TplManDescription = ".SH DESCRIPTION\n%s\n"
+var TplManOptions = ".SH OPTIONS\n%s"
+
+// TplManOption ======================================================
+/*
+.TP
+\fB\-phone\fR [phone number]
+specify user phone number
+*/
+var TplManOption = ".TP\n\\fB%s\\fR%s\n%s\n"
+var TplManSeeAlso = ".SH SEE ALSO\n%s\n"
+var TplManSeeAlsoItem = "\\fB%s\\fR(%s)"
+
+// Generate the roff man page of the Command
+func (c *Command) GenerateMan() string {
+	fullName := c.FullName()
+	date := ManDate
+	if len(date) == 0 {
+		date = time.Now().Format("January 2006")
+	}
+	page := fmt.Sprintf(TplManHeader, strings.ToUpper(manName(fullName)), ManSection, date, ManSource, ManManual)
+
+	page += fmt.Sprintf(TplManName, manEscape(manName(fullName)), manEscape(c.DescribeBrief))
+
+	synopsis := ""
+	commands := c.visibleCommands()
+	options := c.visibleOptions()
+	if c.runnable() || len(commands) == 0 {
+		usage := ""
+		if len(c.Usage) != 0 {
+			usage = manEscape(c.Usage) + "\n"
+		}
+		synopsis += fmt.Sprintf(TplManSynopsisLine, manEscape(fullName), usage)
+	}
+	if len(commands) != 0 {
+		synopsis += fmt.Sprintf(TplManSynopsisLine, manEscape(fullName), "<command> [arguments]\n")
+	}
+	if len(options) != 0 {
+		synopsis += fmt.Sprintf(TplManSynopsisLine, manEscape(fullName), "<option> [arguments]\n")
+	}
+	page += fmt.Sprintf(TplManSynopsis, synopsis)
+
+	if len(strings.TrimSpace(c.Describe)) != 0 {
+		page += fmt.Sprintf(TplManDescription, manEscape(c.Describe))
+	}
+
+	if len(options) != 0 {
+		list := ""
+		for _, v := range options {
+			describe := v.Describe
+			if len(strings.TrimSpace(describe)) == 0 {
+				describe = v.DescribeBrief
+			}
+			usage := ""
+			if len(v.Usage) != 0 {
+				usage = " " + manEscape(v.Usage)
+			}
+			list += fmt.Sprintf(TplManOption, manEscape(v.Name), usage, manEscape(describe))
+		}
+		page += fmt.Sprintf(TplManOptions, list)
+	}
+
+	seeAlso := make([]string, 0)
+	if len(c.Father) != 0 {
+		seeAlso = append(seeAlso, fmt.Sprintf(TplManSeeAlsoItem, manEscape(manName(c.Father)), ManSection))
+	}
+	for _, v := range commands {
+		seeAlso = append(seeAlso, fmt.Sprintf(TplManSeeAlsoItem, manEscape(manName(v.FullName())), ManSection))
+	}
+	if len(seeAlso) != 0 {
+		page += fmt.Sprintf(TplManSeeAlso, strings.Join(seeAlso, ",\n"))
+	}
+	return page
+}
+
+// Write the man pages of the Command and all its sub commands to dir
+//
+// The file name is the full name joined by "-", like "go-mod-download.1"
+func (c *Command) GenerateManTree(dir string) error {
+	err := ioutil.WriteFile(filepath.Join(dir, manName(c.FullName())+"."+ManSection), []byte(c.GenerateMan()), 0644)
+	if err != nil {
+		return err
+	}
+	for _, v := range c.visibleCommands() {
+		if err = v.GenerateManTree(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name of the man page, "go mod download" is "go-mod-download"
+func manName(fullName string) string {
+	return filepath.Base(strings.Replace(fullName, " ", "-", -1))
+}
+
+// Escape text for roff
+func manEscape(s string) string {
+	s = strings.Replace(s, "\\", "\\e", -1)
+	s = strings.Replace(s, "-", "\\-", -1)
+	lines := strings.Split(s, "\n")
+	for k, v := range lines {
+		if strings.HasPrefix(v, ".") || strings.HasPrefix(v, "'") {
+			lines[k] = "\\&" + v
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package arg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Front matter of the generated Markdown file, like "---\ntitle: fi\n---\n\n"
+var MarkdownFrontMatter func(c *Command) string
+
+// Link to the Markdown file of the Command, like "fi-version.md"
+var MarkdownLink = func(c *Command) string {
+	return manName(c.FullName()) + ".md"
+}
+
+var TplMdTitle = "%s %s\n\n%s\n\n"
+var TplMdSynopsis = "%s# Synopsis\n\n%s\n\n"
+var TplMdUsage = "```\n%s```\n\n"
+var TplMdOptions = "%s# Options\n\n| Name | Arguments | Default | Env | Description |\n| --- | --- | --- | --- | --- |\n%s\n"
+var TplMdOption = "| `%s` | %s | %s | %s | %s |\n"
+var TplMdExamples = "%s# Examples\n\n```\n%s\n```\n\n"
+var TplMdCommands = "%s# Commands\n\n%s\n"
+var TplMdSeeAlso = "%s# See Also\n\n%s\n"
+var TplMdLink = "* [%s](%s)%s\n"
+
+// Generate the Markdown reference of the Command
+func (c *Command) GenerateMarkdown() string {
+	front := ""
+	if MarkdownFrontMatter != nil {
+		front = MarkdownFrontMatter(c)
+	}
+	return front + c.markdown("#", MarkdownLink)
+}
+
+// Write the Markdown reference of the Command and all its sub commands to dir,
+// one file for each command
+//
+// The file name is the full name joined by "-", like "go-mod-download.md"
+func (c *Command) GenerateMarkdownTree(dir string) error {
+	err := ioutil.WriteFile(filepath.Join(dir, manName(c.FullName())+".md"), []byte(c.GenerateMarkdown()), 0644)
+	if err != nil {
+		return err
+	}
+	for _, v := range c.visibleCommands() {
+		if err = v.GenerateMarkdownTree(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Generate the Markdown reference of the Command and all its sub commands in one file
+func (c *Command) GenerateMarkdownSingle() string {
+	front := ""
+	if MarkdownFrontMatter != nil {
+		front = MarkdownFrontMatter(c)
+	}
+	link := func(c *Command) string {
+		return "#" + mdAnchor(c.FullName())
+	}
+	var walk func(c *Command) string
+	walk = func(c *Command) string {
+		page := c.markdown("##", link)
+		for _, v := range c.visibleCommands() {
+			page += walk(v)
+		}
+		return page
+	}
+	return front + walk(c)
+}
+
+// Markdown of the Command, title starts with heading
+func (c *Command) markdown(heading string, link func(*Command) string) string {
+	fullName := c.FullName()
+	page := fmt.Sprintf(TplMdTitle, heading, fullName, c.DescribeBrief)
+
+	commands := c.visibleCommands()
+	options := c.visibleOptions()
+	usage := ""
+	if c.runnable() || len(commands) == 0 {
+		usage += strings.TrimRight(fullName+" "+c.Usage, " ") + "\n"
+	}
+	if len(commands) != 0 {
+		usage += fullName + " <command> [arguments]\n"
+	}
+	if len(options) != 0 {
+		usage += fullName + " <option> [arguments]\n"
+	}
+	synopsis := fmt.Sprintf(TplMdUsage, usage)
+	if len(strings.TrimSpace(c.Describe)) != 0 {
+		synopsis = c.Describe + "\n\n" + synopsis
+	}
+	page += fmt.Sprintf(TplMdSynopsis, heading, strings.TrimRight(synopsis, "\n"))
+
+	if len(options) != 0 {
+		list := ""
+		for _, v := range options {
+			describe := v.DescribeBrief
+			if len(strings.TrimSpace(describe)) == 0 {
+				describe = v.Describe
+			}
+			list += fmt.Sprintf(TplMdOption, v.Name, mdCell(v.Usage), mdCell(v.Default),
+				mdCell(v.Env), mdCell(describe))
+		}
+		page += fmt.Sprintf(TplMdOptions, heading, list)
+	}
+
+	if len(strings.TrimSpace(c.Example)) != 0 {
+		page += fmt.Sprintf(TplMdExamples, heading, strings.Trim(c.Example, "\n"))
+	}
+
+	if len(commands) != 0 {
+		list := ""
+		for _, v := range commands {
+			list += mdLink(v, link)
+		}
+		page += fmt.Sprintf(TplMdCommands, heading, list)
+	}
+
+	if len(c.Father) != 0 {
+		father := c.father()
+		if father == nil {
+			father = NewCommand(c.Father, "")
+		}
+		page += fmt.Sprintf(TplMdSeeAlso, heading, mdLink(father, link))
+	}
+	return page
+}
+
+// Markdown list item linked to the Command
+func mdLink(c *Command, link func(*Command) string) string {
+	brief := ""
+	if len(c.DescribeBrief) != 0 {
+		brief = " - " + c.DescribeBrief
+	}
+	return fmt.Sprintf(TplMdLink, c.FullName(), link(c), brief)
+}
+
+// Escape text for a Markdown table cell
+func mdCell(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(strings.TrimSpace(s), "\n", "<br>", -1)
+}
+
+// Anchor of the heading text, like "fi-build" for "./fi build"
+//
+// Letters and digits are lower cased, spaces are replaced by "-",
+// other characters except "-" and "_" are removed, like GitHub and most static site generators
+func mdAnchor(s string) string {
+	b := strings.Builder{}
+	for _, v := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(v) || unicode.IsDigit(v) || v == '-' || v == '_':
+			b.WriteRune(v)
+		case v == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package arg
+
+// Middleware wraps an executor, like logging, timing or auth checks
+type Middleware func(FuncExecutor) FuncExecutor
+
+// Middlewares of all commands and options, the first is the outermost
+var middlewares = make([]Middleware, 0)
+
+// The Command and Option being wrapped, only set in wrapExecutor, which is never called at the same time
+var wrappingCommand *Command
+var wrappingOption *Option
+
+// Use
+//
+// add middlewares for the executors of all commands and options
+func Use(m ...Middleware) {
+	middlewares = append(middlewares, m...)
+}
+
+// Add middlewares for the executors of the Command, its options and sub commands
+func (c *Command) Use(m ...Middleware) {
+	c.Middlewares = append(c.Middlewares, m...)
+}
+
+// WithTarget
+//
+// make a Middleware which sees the Command and Option it wraps,
+// opt is nil for the Executor of cmd. They are captured when the executor is wrapped,
+// so the executor returned by f can use them when it runs
+func WithTarget(f func(cmd *Command, opt *Option, next FuncExecutor) FuncExecutor) Middleware {
+	return func(next FuncExecutor) FuncExecutor {
+		return f(wrappingCommand, wrappingOption, next)
+	}
+}
+
+// Wrap executor of opt with the middlewares of cmd, opt is nil for the Executor of cmd
+//
+// Middlewares of the package are outermost, then those of the commands from RootCommand to cmd
+func wrapExecutor(cmd *Command, opt *Option, executor FuncExecutor) FuncExecutor {
+	if executor == nil {
+		return nil
+	}
+	chain := append(make([]Middleware, 0), middlewares...)
+	for _, c := range cmd.chain() {
+		chain = append(chain, c.Middlewares...)
+	}
+	wrappingCommand, wrappingOption = cmd, opt
+	defer func() {
+		wrappingCommand, wrappingOption = nil, nil
+	}()
+	for i := len(chain) - 1; i >= 0; i-- {
+		executor = chain[i](executor)
+	}
+	return executor
+}
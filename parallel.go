@@ -0,0 +1,115 @@
+package arg
+
+import (
+	"context"
+	"sync"
+)
+
+// Execute the options with the same priority in goroutines, see Option.Concurrent
+var Parallel = false
+
+// Maximum number of options executed at the same time, no limit if it is not positive
+var MaxParallel = 0
+
+// Split the works into batches, the works in a batch are executed at the same time
+//
+// Consecutive works which are Concurrent, or have the same Priority in Parallel mode,
+// are in a batch if there is no dependency between them
+func (q *workQueue) batches() [][]int {
+	res := make([][]int, 0)
+	for i, w := range *q {
+		if k := len(res) - 1; k >= 0 && q.joinable(res[k], w) {
+			res[k] = append(res[k], i)
+			continue
+		}
+		res = append(res, []int{i})
+	}
+	return res
+}
+
+// Whether w can be executed with the works of batch at the same time
+func (q *workQueue) joinable(batch []int, w work) bool {
+	for _, i := range batch {
+		v := (*q)[i]
+		if !(v.Option.Concurrent && w.Option.Concurrent) && !(Parallel && v.Priority == w.Priority) {
+			return false
+		}
+		if v.Option.dependsOn(w.Option) || w.Option.dependsOn(v.Option) {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether o must run before or after opt
+func (o *Option) dependsOn(opt *Option) bool {
+	return contains(o.After, opt.Name) || contains(o.Before, opt.Name)
+}
+
+// Execute the works of batch, at most MaxParallel at the same time
+//
+// The error of (*q)[i] is put in errs[i], cancel is called on the first error if cont is false,
+// works not started yet are skipped then. The index of the work which called cancel is returned,
+// -1 if cancel is not called
+func (q *workQueue) execBatch(ctx context.Context, cancel context.CancelFunc, batch []int, errs []error,
+	cont bool) (cause int) {
+	cause = -1
+	mu := sync.Mutex{}
+	executors := make([]FuncExecutor, len(batch))
+	for k, i := range batch {
+		// wrap in order, middlewares are not called at the same time
+		executors[k] = (*q)[i].executor(ctx)
+	}
+	exec := func(k int) {
+		w := (*q)[batch[k]]
+		err := call(w.Option.command(), w.Option, executors[k], w.Args)
+		if handler := w.Option.errorHandler(ctx); err != nil && handler != nil {
+			err = handler(err)
+		}
+		errs[batch[k]] = err
+		(*q)[batch[k]].Done = err == nil
+		if err != nil && !cont {
+			mu.Lock()
+			if cause == -1 {
+				cause = batch[k]
+				cancel()
+			}
+			mu.Unlock()
+		}
+	}
+	if len(batch) == 1 {
+		if executors[0] != nil {
+			exec(0)
+		}
+		return cause
+	}
+
+	size := MaxParallel
+	if size <= 0 {
+		size = len(batch)
+	}
+	sem := make(chan struct{}, size)
+	wg := sync.WaitGroup{}
+	for k := range batch {
+		if executors[k] == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(k int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			exec(k)
+		}(k)
+	}
+	wg.Wait()
+	return cause
+}
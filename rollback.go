@@ -0,0 +1,40 @@
+package arg
+
+// Undo the options succeeded if err is not nil, then clean up them
+//
+// Rollback of the options are called in reverse order of execution if err is not nil,
+// then Finally of the options, also in reverse order.
+// Their errors are returned after err as MultiError, a single error is returned as is
+func (q *workQueue) unwind(err error) error {
+	errs := make(MultiError, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	undo := func(w work, f FuncExecutor) {
+		if f == nil {
+			return
+		}
+		if e := call(w.Option.command(), w.Option, f, w.Args); e != nil {
+			errs = append(errs, e)
+		}
+	}
+	if err != nil {
+		for i := len(*q) - 1; i >= 0; i-- {
+			if w := (*q)[i]; w.Done {
+				undo(w, w.Option.Rollback)
+			}
+		}
+	}
+	for i := len(*q) - 1; i >= 0; i-- {
+		if w := (*q)[i]; w.Done {
+			undo(w, w.Option.Finally)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errs
+}
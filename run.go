@@ -0,0 +1,82 @@
+package arg
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// Run the Command with args and ctx after the options in the queue
+//
+// The hooks are run in order:
+//
+//	PersistentPreRun of the commands, from RootCommand to cmd
+//	PreRun of cmd
+//	Executor of cmd
+//	PostRun of cmd
+//	PersistentPostRun of the commands, from cmd to RootCommand
+//
+// A post hook runs if its pre hook succeeded, even if the Executor or a later hook failed.
+// The first error is passed to the ErrorHandler of cmd
+func run(ctx context.Context, cmd *Command, args []string) (err error) {
+	post := make([]FuncExecutor, 0)
+	defer func() {
+		for i := len(post) - 1; i >= 0; i-- {
+			if e := post[i](args); e != nil && err == nil {
+				err = e
+			}
+		}
+		if handler := cmd.errorHandler(ctx); err != nil && handler != nil {
+			err = handler(err)
+		}
+	}()
+
+	for _, c := range cmd.chain() {
+		if c.PersistentPreRun != nil {
+			if err = c.PersistentPreRun(args); err != nil {
+				return err
+			}
+		}
+		if c.PersistentPostRun != nil {
+			post = append(post, c.PersistentPostRun)
+		}
+	}
+	if cmd.PreRun != nil {
+		if err = cmd.PreRun(args); err != nil {
+			return err
+		}
+	}
+	if cmd.PostRun != nil {
+		post = append(post, cmd.PostRun)
+	}
+	if cmd.runnable() {
+		err = execTimeout(ctx, cmd, args)
+	}
+	return err
+}
+
+// Call executor of opt, the panic is recovered as ExecutorPanicError if RecoverPanics is true
+//
+// opt is nil for the Executor of cmd
+func call(cmd *Command, opt *Option, executor FuncExecutor, args []string) (err error) {
+	if RecoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				e := &ExecutorPanicError{Value: v, Stack: debug.Stack(), Command: cmd.FullName()}
+				if opt != nil {
+					e.Option = opt.Name
+				}
+				err = e
+			}
+		}()
+	}
+	return executor(args)
+}
+
+// The Command and its fathers, from RootCommand to c
+func (c *Command) chain() []*Command {
+	res := make([]*Command, 0)
+	for cmd := c; cmd != nil; cmd = cmd.father() {
+		res = append([]*Command{cmd}, res...)
+	}
+	return res
+}
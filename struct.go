@@ -1,8 +1,14 @@
 package arg
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strings"
+	"time"
 )
 
 type FuncErrorHandler func(error) error
@@ -12,51 +18,90 @@ type FuncExecutor func([]string) error
 type work struct {
 	// Priority for work
 	Priority int
-	// Work Executor
-	Executor FuncExecutor
-	// Error Handler, If Executor return err
-	ErrorHandler FuncErrorHandler
 	// Arguments for Executor
 	Args []string
+	// The Option of the work
+	Option *Option
+	// The work is executed successfully
+	Done bool
 }
 
 // Work Queue
 type workQueue []work
 
 // Sort Work Queue
-// Big first
+// Big first, then by Option.After and Option.Before, works keep their order if equal
 func (q *workQueue) sort() {
-	sort.Slice((*q)[:], func(i, j int) bool {
+	sort.SliceStable((*q)[:], func(i, j int) bool {
 		return (*q)[i].Priority > (*q)[j].Priority
 	})
+	q.order()
 }
 
-// Add a work to Work Queue
-func (q *workQueue) add(priority int, executor FuncExecutor, errExecutor FuncErrorHandler, args []string) {
+// Add a work of the Option to Work Queue
+func (q *workQueue) add(opt *Option, args []string) {
 	*q = append(*q, work{
-		Priority:     priority,
-		Executor:     executor,
-		ErrorHandler: errExecutor,
-		Args:         args,
+		Priority: opt.Priority,
+		Args:     args,
+		Option:   opt,
 	})
 }
 
 // Exec All work, Start execution from the first item in the Work Queue
-func (q *workQueue) exec() (err error) {
-	for _, v := range *q {
-		if v.Executor != nil {
-			err = v.Executor(v.Args)
-			if err != nil {
-				if v.ErrorHandler != nil {
-					return v.ErrorHandler(err)
-				}
-				return err
+//
+// If cont is true, the rest works are executed after a failure and the errors are returned as MultiError,
+// otherwise the first error in the Work Queue is returned, not the errors caused by its cancellation.
+// If ctx is done, the rest works are skipped and ctx.Err() is returned after the errors of the works
+func (q *workQueue) exec(parent context.Context, cont bool) (err error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	res := make([]error, len(*q))
+	for _, v := range q.batches() {
+		if ctx.Err() != nil {
+			break
+		}
+		if cause := q.execBatch(ctx, cancel, v, res, cont); cause != -1 {
+			return firstError(res, res[cause], parent.Err() == nil)
+		}
+	}
+	errs := make(MultiError, 0)
+	for _, v := range res {
+		if v != nil {
+			if !cont {
+				return v
 			}
+			errs = append(errs, v)
 		}
 	}
+	if parent.Err() != nil {
+		if !cont {
+			return parent.Err()
+		}
+		errs = append(errs, parent.Err())
+	}
+	if len(errs) != 0 {
+		return errs
+	}
 	return nil
 }
 
+// The first error in res, in order of the Work Queue, cause if there is no other error
+//
+// If own is true, context.Canceled is caused by cause, it is not the first error
+func firstError(res []error, cause error, own bool) error {
+	for _, v := range res {
+		if v != nil && !(own && errors.Is(v, context.Canceled)) {
+			return v
+		}
+	}
+	return cause
+}
+
+// Executor of the work with ctx, wrapped by middlewares
+func (w *work) executor(ctx context.Context) FuncExecutor {
+	return wrapExecutor(w.Option.command(), w.Option, w.Option.executor(ctx))
+}
+
 // Command
 type Command struct {
 	Order         int
@@ -76,11 +121,127 @@ type Command struct {
 	Size         int
 	Executor     FuncExecutor
 	ErrorHandler FuncErrorHandler
+	// Executor and ErrorHandler with the context of ParseContext, used instead if not nil
+	ContextExecutor     FuncContextExecutor
+	ContextErrorHandler FuncContextErrorHandler
+	// Candidates for the arguments of this command
+	Completer FuncCompleter
+	// Examples shown in generated documentation
+	Example string
+	// Template of Help, HelpTemplate if empty
+	HelpTemplate string
+	// Reject arguments start with "-" which are not options, numbers or "-"
+	Strict bool
+	// Writers of help and error messages of the Command and its sub commands,
+	// the package Out and Err if nil
+	Out io.Writer
+	Err io.Writer
+	// Hidden from help, documentation and completion
+	Hidden bool
+	// Deprecation message, like "use publish instead", a warning is printed when it is used
+	Deprecated string
+	// Section of the Command in the help of its father, like "Management commands"
+	Group string
+	// Order of the sections in help, GroupOrder if nil
+	GroupOrder []string
+	// Hooks around Executor, PreRun and PostRun run for this Command only,
+	// PersistentPreRun and PersistentPostRun run for this Command and its sub commands
+	PreRun            FuncExecutor
+	PostRun           FuncExecutor
+	PersistentPreRun  FuncExecutor
+	PersistentPostRun FuncExecutor
+	// Execute all the options even if some of them failed, see ContinueOnError
+	ContinueOnError bool
+	// Deadline of the context of ContextExecutor, ErrTimeout is passed to ErrorHandler if it is exceeded,
+	// no timeout if it is 0, see TimeoutOption. Executor without context never times out
+	Timeout time.Duration
+	// Run the executors in dry-run mode after the plan is printed, they check IsDryRun to simulate
+	Simulate bool
+	// Middlewares of the executors of the Command, its options and sub commands
+	Middlewares []Middleware
+
+	// Generated Help, plain and colorized
+	helpGenerated string
+	helpColored   string
+
+	// The deprecation warning is printed
+	warned bool
+
+	// The Command which it is added to by Add, Father is only for display
+	parent *Command
+}
+
+// Full name of the Command, like "go mod download"
+func (c *Command) FullName() string {
+	if len(c.Father) == 0 {
+		return c.Name
+	}
+	return c.Father + " " + c.Name
+}
+
+// Whether the Command has an executor
+func (c *Command) runnable() bool {
+	return c.Executor != nil || c.ContextExecutor != nil
+}
+
+// Whether the option queue of the Command continues after a failure
+func (c *Command) continueOnError() bool {
+	return ContinueOnError || c.ContinueOnError
+}
+
+// Whether the Command is in strict mode
+func (c *Command) isStrict() bool {
+	return StrictMode || c.Strict
 }
 
 // Print Help
 func (c *Command) PrintHelp() {
-	fmt.Print(c.Help)
+	w := c.out()
+	if len(c.helpColored) != 0 && c.Help == c.helpGenerated && colorEnabled(w) {
+		_, _ = fmt.Fprint(w, c.helpColored)
+		return
+	}
+	_, _ = fmt.Fprint(w, c.Help)
+}
+
+// Writer of help, Out of the Command or its nearest father, then the package Out
+func (c *Command) out() io.Writer {
+	for cmd := c; cmd != nil; cmd = cmd.father() {
+		if cmd.Out != nil {
+			return cmd.Out
+		}
+	}
+	if Out != nil {
+		return Out
+	}
+	return os.Stdout
+}
+
+// Writer of error messages, Err of the Command or its nearest father, then the package Err
+func (c *Command) errOut() io.Writer {
+	for cmd := c; cmd != nil; cmd = cmd.father() {
+		if cmd.Err != nil {
+			return cmd.Err
+		}
+	}
+	if Err != nil {
+		return Err
+	}
+	return os.Stderr
+}
+
+// The Command which c belongs to, nil for RootCommand
+//
+// It is the Command which c is added to by Add,
+// or found by Father if c is put in Commands directly
+func (c *Command) father() *Command {
+	if c.parent != nil {
+		return c.parent
+	}
+	if len(c.Father) == 0 {
+		return nil
+	}
+	return findCommand(c.Father)
 }
 
 // Generate Help
@@ -103,118 +264,16 @@ func (c *Command) GenerateHelp() {
 			v.GenerateHelp()
 		}
 	}
-	fullName := func() (name string) {
-		name = c.Father
-		if len(name) == 0 {
-			name = c.Name
-		} else {
-			name += " " + c.Name
-		}
-		return
-	}()
-	describe := func() string {
-		up := fmt.Sprintf(TplDescribeUp, fullName)
-		down := fmt.Sprintf(TplDescribeDown, c.Describe)
-		fg := true
-		for _, v := range down {
-			if v != ' ' && v != '\n' {
-				fg = false
-				break
-			}
-		}
-		if fg {
-			down = ""
-		}
-		return up + down
-	}()
-	cmd := false
-	opt := false
-	usageHead := func() string {
-		hCommand := ""
-		hOptionUp := ""
-		hOptionDown := ""
-		if c.Executor != nil {
-			if c.Options != nil && len(c.Options) != 0 {
-				hOptionDown = fmt.Sprintf(TplCommandUsageOption, fullName)
-				opt = true
-			} else {
-				hOptionUp = fmt.Sprintf(TplCommandUsageSelf, fullName, c.Usage)
-			}
-		}
-
-		if c.Commands != nil && len(c.Commands) != 0 {
-			hCommand = fmt.Sprintf(TplCommandUsageCommand, fullName)
-			cmd = true
-		}
-		return fmt.Sprintf(TplUsage, hOptionUp+hCommand+hOptionDown)
-	}()
-
-	commands := ""
-	if cmd {
-		cmdLine := ""
-		lMax := 0
-		for _, v := range c.Commands {
-			if lMax < len(v.Name) {
-				lMax = len(v.Name)
-			}
-		}
-		lines := make(Lines, 0)
-		for _, v := range c.Commands {
-			lines = append(lines, Line{
-				Order: v.Order,
-				Line:  fmt.Sprintf(fmt.Sprintf(HTplLineCommand, lMax), v.Name, v.DescribeBrief),
-			})
-		}
-		lines.Sort()
-		for _, v := range lines {
-			cmdLine += v.Line
-		}
-		h := ""
-		T := true
-		for key := range HelpCommandArgs {
-			if T {
-				h += key
-				T = false
-			} else {
-				h += "/" + key
-			}
-		}
-		commands = fmt.Sprintf(HTplCommandList, cmdLine, fullName, h)
+	tpl := c.HelpTemplate
+	if len(tpl) == 0 {
+		tpl = HelpTemplate
 	}
-
-	options := ""
-	if opt {
-		optLine := ""
-		lMax := 0
-		for _, v := range c.Options {
-			if lMax < len(v.Name) {
-				lMax = len(v.Name)
-			}
-		}
-		lines := make(Lines, 0)
-		for _, v := range c.Options {
-			lines = append(lines, Line{
-				Order: v.Order,
-				Line:  fmt.Sprintf(fmt.Sprintf(HTplLineOption, lMax, lMax), v.Name, v.Usage, " ", v.DescribeBrief),
-			})
-		}
-		lines.Sort()
-		for _, v := range lines {
-			optLine += v.Line
-		}
-		h := ""
-		T := true
-		for key := range HelpCommandArgs {
-			if T {
-				h += key
-				T = false
-			} else {
-				h += "/" + key
-			}
-		}
-		options = fmt.Sprintf(HTplOptionList, optLine, fullName, h)
+	view := c.helpView()
+	c.Help = renderHelp(tpl, view, nil)
+	c.helpGenerated = c.Help
+	if ColorTheme != nil {
+		c.helpColored = renderHelp(tpl, view, ColorTheme)
 	}
-	c.Help = fmt.Sprintf(TplHelp, describe, usageHead, commands, options)
 }
 
 // Create a new Command
@@ -272,11 +331,75 @@ type Option struct {
 	Usage         string
 	Executor      FuncExecutor
 	ErrorExecutor FuncErrorHandler
+	// Executor and ErrorExecutor with the context of ParseContext, used instead if not nil
+	ContextExecutor      FuncContextExecutor
+	ContextErrorExecutor FuncContextErrorHandler
+	// Candidates for the arguments of this option
+	Completer FuncCompleter
+	// Default value and environment variable shown in generated documentation
+	Default string
+	Env     string
+	// Template of Help, OptionHelpTemplate if empty
+	HelpTemplate string
+	// Hidden from help, documentation and completion
+	Hidden bool
+	// Deprecation message, like "use -output instead", a warning is printed when it is used
+	Deprecated string
+	// Section of the Option in help, like "Output options"
+	Group string
+	// Names of the options of the same command, which run before or after this Option if given,
+	// they take precedence over Priority
+	After  []string
+	Before []string
+	// Execute with the consecutive Concurrent options in goroutines
+	Concurrent bool
+	// Called with the arguments of the Executor if it succeeded,
+	// Rollback if a later option or the command failed, in reverse order, then Finally
+	Rollback FuncExecutor
+	Finally  FuncExecutor
+
+	// Execute immediately and stop parsing, like the version option
+	terminal bool
+
+	// Generated Help, plain and colorized
+	helpGenerated string
+	helpColored   string
+
+	// The deprecation warning is printed
+	warned bool
+
+	// The Command which it is added to by Add, Father is only for display
+	parent *Command
+}
+
+// Full name of the Option, like "go mod -version"
+func (o *Option) FullName() string {
+	if len(o.Father) == 0 {
+		return o.Name
+	}
+	return o.Father + " " + o.Name
 }
 
 // Print Help
 func (o *Option) PrintHelp() {
-	fmt.Print(o.Help)
+	w := o.command().out()
+	if len(o.helpColored) != 0 && o.Help == o.helpGenerated && colorEnabled(w) {
+		_, _ = fmt.Fprint(w, o.helpColored)
+		return
+	}
+	_, _ = fmt.Fprint(w, o.Help)
+}
+
+// The Command which o belongs to, the Command which o is added to by Add, or found by Father,
+// RootCommand if it is not found
+func (o *Option) command() *Command {
+	if o.parent != nil {
+		return o.parent
+	}
+	if c := findCommand(o.Father); c != nil {
+		return c
+	}
+	return RootCommand
 }
 
 // Generate Help
@@ -284,17 +407,19 @@ func (o *Option) GenerateHelp() {
 	if len(o.Help) != 0 {
 		return
 	}
-	fullName := func() (name string) {
-		name = o.Father
-		if len(name) == 0 {
-			name = o.Name
-		} else {
-			name += " " + o.Name
-		}
-		return
-	}()
-	//describe := fmt.Sprintf(TplDescribe, fullName, o.Describe)
-	o.Help = fmt.Sprintf(HTplOptionUsage, fullName+" "+o.Usage, o.Describe)
+	tpl := o.HelpTemplate
+	if len(tpl) == 0 {
+		tpl = OptionHelpTemplate
+	}
+	view := OptionHelpView{
+		HelpEntry: o.helpEntry(),
+		Width:     helpWidth(o.command().out()),
+	}
+	o.Help = renderHelp(tpl, view, nil)
+	o.helpGenerated = o.Help
+	if ColorTheme != nil {
+		o.helpColored = renderHelp(tpl, view, ColorTheme)
+	}
 }
 
 // Create a new Option
@@ -337,16 +462,79 @@ func NewOptions() map[string]*Option {
 	return make(map[string]*Option)
 }
 
+// Line of help before HelpTemplate
+//
+// Deprecated: not used, help is rendered with HelpTemplate.
 type Line struct {
 	Order int
 	Line  string
 }
 
+// Lines of help before HelpTemplate
+//
+// Deprecated: not used, help is rendered with HelpTemplate.
 type Lines []Line
 
 // Little first
+//
+// Deprecated: not used, help is rendered with HelpTemplate.
 func (l *Lines) Sort() {
 	sort.Slice((*l)[:], func(i, j int) bool {
 		return (*l)[i].Order < (*l)[j].Order
 	})
 }
+
+// Sub commands not Hidden, sorted by Order, then by Name
+func (c *Command) visibleCommands() []*Command {
+	names := make([]string, 0, len(c.Commands))
+	for k, v := range c.Commands {
+		if !v.Hidden {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	res := make([]*Command, 0, len(names))
+	for _, v := range names {
+		res = append(res, c.Commands[v])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Order < res[j].Order
+	})
+	return res
+}
+
+// Options not Hidden, sorted by Order, then by Name
+func (c *Command) visibleOptions() []*Option {
+	names := make([]string, 0, len(c.Options))
+	for k, v := range c.Options {
+		if !v.Hidden {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	res := make([]*Option, 0, len(names))
+	for _, v := range names {
+		res = append(res, c.Options[v])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Order < res[j].Order
+	})
+	return res
+}
+
+// Find the Command by full name, like "go mod download", start from RootCommand
+func findCommand(fullName string) *Command {
+	if fullName == RootCommand.Name {
+		return RootCommand
+	}
+	if !strings.HasPrefix(fullName, RootCommand.Name+" ") {
+		return nil
+	}
+	cmd := RootCommand
+	for _, v := range strings.Fields(fullName[len(RootCommand.Name):]) {
+		if cmd = cmd.Commands[v]; cmd == nil {
+			return nil
+		}
+	}
+	return cmd
+}
@@ -0,0 +1,127 @@
+package arg
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Maximum edit distance between a mistyped argument and the suggested names,
+// set it to 0 to disable suggestions
+var SuggestionDistance = 2
+
+// The error for a mistyped command or option, nil if arg is a positional argument
+//
+// An argument starts with "-" is checked against the options,
+// the first argument of the command is checked against the sub commands.
+// Numbers, like "-1", and "-" are positional arguments.
+// In strict mode, other arguments start with "-" are unknown options
+func unknownArg(cmd *Command, arg string, first bool) *ParseError {
+	if strings.HasPrefix(arg, "-") {
+		if isNumber(arg) || arg == "-" {
+			// negative numbers and stdin are positional arguments
+			return nil
+		}
+		names := make([]string, 0, len(cmd.Options))
+		for k, v := range cmd.Options {
+			if !v.Hidden {
+				names = append(names, k)
+			}
+		}
+		if s := suggestions(arg, names); len(s) != 0 {
+			return &ParseError{Err: ErrUnknownOption, Token: arg, Command: cmd.FullName(), Suggestions: s}
+		}
+		if cmd.isStrict() {
+			return &ParseError{Err: ErrUnknownOption, Token: arg, Command: cmd.FullName()}
+		}
+		return nil
+	}
+	if first {
+		names := make([]string, 0, len(cmd.Commands))
+		for k, v := range cmd.Commands {
+			if !v.Hidden {
+				names = append(names, k)
+			}
+		}
+		if s := suggestions(arg, names); len(s) != 0 {
+			return &ParseError{Err: ErrUnknownCommand, Token: arg, Command: cmd.FullName(), Suggestions: s}
+		}
+	}
+	return nil
+}
+
+// Whether arg is a number, like "-1" or "-0.5"
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+// The names close to arg, the closest first
+//
+// Dashes are ignored, the distance must be at most half of the name and SuggestionDistance.
+// A name of one letter only matches arg in other case
+func suggestions(arg string, names []string) []string {
+	if SuggestionDistance <= 0 {
+		return nil
+	}
+	distance := make(map[string]int)
+	res := make([]string, 0)
+	a := strings.TrimLeft(arg, "-")
+	for _, v := range names {
+		// compare without dashes, "-n" is far from "-o"
+		n := strings.TrimLeft(v, "-")
+		size := len([]rune(n))
+		if size <= 1 {
+			// only "-V" for "-v"
+			if a != n && strings.EqualFold(a, n) {
+				distance[v] = 1
+				res = append(res, v)
+			}
+			continue
+		}
+		limit := size / 2
+		if limit > SuggestionDistance {
+			limit = SuggestionDistance
+		}
+		if d := editDistance(a, n); d <= limit {
+			distance[v] = d
+			res = append(res, v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if distance[res[i]] != distance[res[j]] {
+			return distance[res[i]] < distance[res[j]]
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package arg
+
+// Width of the terminal on fd, false if fd is not a terminal
+func terminalWidth(fd uintptr) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package arg
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Width of the terminal on fd, false if fd is not a terminal
+func terminalWidth(fd uintptr) (int, bool) {
+	ws := &struct {
+		Row    uint16
+		Col    uint16
+		Xpixel uint16
+		Ypixel uint16
+	}{}
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(ws)))
+	if e != 0 {
+		return 0, false
+	}
+	return int(ws.Col), true
+}
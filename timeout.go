@@ -0,0 +1,93 @@
+package arg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Name of the built-in option to set the timeout of the command, like "--timeout=30s" or "--timeout 30s",
+// disabled if empty
+var TimeoutOption = ""
+
+// Timeout given by TimeoutOption, Command.Timeout is used if it is 0
+var timeoutArg time.Duration
+
+// Add the built-in option "--timeout" to all commands
+func EnableTimeout() {
+	TimeoutOption = "--timeout"
+}
+
+// Parse TimeoutOption at args[0] and set timeoutArg, return the number of arguments used
+func parseTimeoutOption(cmd *Command, args []string) (int, *ParseError) {
+	if len(TimeoutOption) == 0 {
+		return 0, nil
+	}
+	n := 0
+	value := ""
+	switch {
+	case args[0] == TimeoutOption:
+		if len(args) < 2 {
+			return 0, &ParseError{
+				Err:      ErrNeedMoreArguments,
+				Args:     arguments,
+				Token:    args[0],
+				Index:    argIndex(args),
+				Command:  cmd.FullName(),
+				Option:   TimeoutOption,
+				Expected: 1,
+			}
+		}
+		n, value = 2, args[1]
+	case strings.HasPrefix(args[0], TimeoutOption+"="):
+		n, value = 1, args[0][len(TimeoutOption)+1:]
+	default:
+		return 0, nil
+	}
+	d, e := time.ParseDuration(value)
+	if e != nil || d <= 0 {
+		if e == nil {
+			e = fmt.Errorf("time: invalid duration %q", value)
+		}
+		return 0, &ParseError{
+			Err:     e,
+			Args:    arguments,
+			Token:   args[n-1],
+			Index:   argIndex(args[n-1:]),
+			Command: cmd.FullName(),
+			Option:  TimeoutOption,
+		}
+	}
+	timeoutArg = d
+	return n, nil
+}
+
+// Timeout of the Command, the value of TimeoutOption first, no timeout if it is not positive
+func (c *Command) timeout() time.Duration {
+	if timeoutArg > 0 {
+		return timeoutArg
+	}
+	return c.Timeout
+}
+
+// Call the executor of cmd with args, the context of ContextExecutor has a deadline if cmd has a timeout
+//
+// It waits for the executor to return, so the hooks and rollbacks never run with it at the same time.
+// Only ContextExecutor can time out, it should return when the context is done,
+// then ErrTimeout is returned if the deadline of cmd is exceeded, not the one of the parent.
+// Executor without context is not interrupted and its result is kept
+func execTimeout(parent context.Context, cmd *Command, args []string) error {
+	d := cmd.timeout()
+	if d <= 0 || cmd.ContextExecutor == nil {
+		return call(cmd, nil, wrapExecutor(cmd, nil, cmd.executor(parent)), args)
+	}
+	ctx, cancel := context.WithTimeout(parent, d)
+	defer cancel()
+	err := call(cmd, nil, wrapExecutor(cmd, nil, cmd.executor(ctx)), args)
+	if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s after %s", ErrTimeout, cmd.FullName(), d)
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package arg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+)
+
+var ErrVersion = errors.New("version")
+
+// Names of the built-in version command and options
+var VersionCommandName = "version"
+var VersionOptionNames = []string{"-version", "--version"}
+
+// Format of the version, "plain" or "json"
+//
+// The version command prints json with its option "-json"
+var VersionFormat = "plain"
+
+// Build time of the application, set it with
+//
+//	-ldflags "-X github.com/AkvicorEdwards/arg.BuildTime=2006-01-02T15:04:05Z"
+var BuildTime = ""
+
+// VersionInfo is the version of the application
+type VersionInfo struct {
+	Version       string `json:"version"`
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"module_version,omitempty"`
+	Revision      string `json:"revision,omitempty"`
+	RevisionTime  string `json:"revision_time,omitempty"`
+	Modified      bool   `json:"modified,omitempty"`
+	BuildTime     string `json:"build_time,omitempty"`
+	GoVersion     string `json:"go_version"`
+}
+
+// TplVersion ======================================================
+/*
+fi 1.2.0
+module: github.com/akvicor/fi v1.2.0
+revision: 5f1c3a2 2026-10-01T08:00:00Z (modified)
+build: 2026-10-02T08:00:00Z
+go: go1.15.15
+*/
+var TplVersion = "%s %s\n"
+var TplVersionModule = "module: %s %s\n"
+var TplVersionRevision = "revision: %s %s%s\n"
+var TplVersionModified = " (modified)"
+var TplVersionBuildTime = "build: %s\n"
+var TplVersionGo = "go: %s\n"
+
+// Add the built-in version command and options to RootCommand
+//
+// They print version, enriched with runtime/debug.ReadBuildInfo if buildInfo is true.
+// The options stop parsing and Parse returns ErrVersion.
+func EnableVersion(version string, buildInfo bool) error {
+	jsonFormat := false
+	err := AddCommand([]string{VersionCommandName}, math.MaxInt32, 0, "Print the version of "+RootCommand.Name,
+		"print version", "", "", func(str []string) error {
+			format := VersionFormat
+			if jsonFormat {
+				format = "json"
+				jsonFormat = false
+			}
+			return printVersion(readVersionInfo(version, buildInfo), format)
+		}, nil)
+	if err != nil {
+		return err
+	}
+	err = AddOption([]string{VersionCommandName, "-json"}, 1, 0, 0, "Print the version in JSON",
+		"print in JSON", "", "", func(str []string) error {
+			jsonFormat = true
+			return nil
+		}, nil)
+	if err != nil {
+		return err
+	}
+	for _, v := range VersionOptionNames {
+		err = AddOption([]string{v}, math.MaxInt32, 0, 0, "Print the version of "+RootCommand.Name,
+			"print version", "", "", func(str []string) error {
+				if err := printVersion(readVersionInfo(version, buildInfo), VersionFormat); err != nil {
+					return err
+				}
+				return ErrVersion
+			}, nil)
+		if err != nil {
+			return err
+		}
+		RootCommand.Options[v].terminal = true
+	}
+	return nil
+}
+
+// The version of the application
+func readVersionInfo(version string, buildInfo bool) VersionInfo {
+	info := VersionInfo{
+		Version:   version,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+	if !buildInfo {
+		return info
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module = bi.Main.Path
+		info.ModuleVersion = bi.Main.Version
+		readVCSInfo(bi, &info)
+	}
+	return info
+}
+
+// Print the version to the Out of RootCommand
+func printVersion(info VersionInfo, format string) error {
+	w := RootCommand.out()
+	if format == "json" {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	msg := fmt.Sprintf(TplVersion, RootCommand.Name, info.Version)
+	if len(info.Module) != 0 {
+		msg += fmt.Sprintf(TplVersionModule, info.Module, info.ModuleVersion)
+	}
+	if len(info.Revision) != 0 {
+		modified := ""
+		if info.Modified {
+			modified = TplVersionModified
+		}
+		msg += fmt.Sprintf(TplVersionRevision, info.Revision, info.RevisionTime, modified)
+	}
+	if len(info.BuildTime) != 0 {
+		msg += fmt.Sprintf(TplVersionBuildTime, info.BuildTime)
+	}
+	msg += fmt.Sprintf(TplVersionGo, info.GoVersion)
+	_, err := fmt.Fprint(w, msg)
+	return err
+}